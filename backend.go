@@ -5,12 +5,13 @@ package log
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	stdlog "log"
 	"os"
-	"runtime"
 	"runtime/debug"
+	"slices"
 	"strings"
 
 	"github.com/fatih/color"
@@ -20,128 +21,356 @@ type Backend struct {
 	log      *stdlog.Logger
 	reg      *Registry
 	tag      string
-	sampler  *Sampler
+	samplers samplerSet
 	config   *Config
 	usecolor bool
-	level    Level
+	level    *LevelVar
+	fields   []Field
+	tracer   TraceExtractor
+	hooks    []Hook
+	redactor *Redactor
+	dedup    *Deduplicator
+	json     bool
 }
 
 var (
-	Log      Logger = New(NewConfig())
-	Disabled Logger = &Backend{level: LevelOff, log: stdlog.New(io.Discard, "", 0)}
+	Log      Logger = newDefault()
+	Disabled Logger = &Backend{level: NewLevelVar(LevelOff), log: stdlog.New(io.Discard, "", 0)}
 )
 
-func init() {
-	// reset color based on env var
-	disableColor := false
-	switch os.Getenv("LOGCOLOR") {
-	case "false", "off", "0":
-		disableColor = true
-	}
-	color.NoColor = color.NoColor || disableColor
-}
-
 const (
-	calldepth = 4
+	calldepth = 6
 	fileFlags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
 )
 
+// newDefault creates the package logger. It only takes level, flags and
+// color settings from the environment so that importing the package never
+// opens files or exits when other settings are invalid.
+func newDefault() *Backend {
+	env := NewConfig()
+	c := defaultConfig()
+	c.Level, c.Flags, c.NoColor = env.Level, env.Flags, env.NoColor
+	return New(c)
+}
+
 func Init(c *Config) {
 	Log = New(c)
 }
 
+// New creates a logger for config c and exits the process when the
+// backend cannot be opened. Use Open to handle errors instead.
 func New(c *Config) *Backend {
+	b, err := newBackend(c)
+	if err != nil {
+		stdlog.Fatalln("FATAL:", err)
+	}
+	return b
+}
+
+// Open validates config c and creates a logger. Unlike New it returns
+// configuration and backend errors to the caller, e.g. to fall back to
+// stderr.
+func Open(c *Config) (Logger, error) {
+	if c == nil {
+		c = NewConfig()
+	}
+	if err := c.Check(); err != nil {
+		return nil, err
+	}
+	b, err := newBackend(c)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func newBackend(c *Config) (*Backend, error) {
 	if c == nil {
 		c = NewConfig()
 	}
 	switch strings.ToLower(c.Backend) {
 	case "file":
-		if c.Filename != "" {
-			file, err := os.OpenFile(c.Filename, fileFlags, c.FileMode)
-			if err != nil {
-				stdlog.Fatalln("FATAL: Cannot open logfile", c.Filename, ":", err.Error())
-			}
-			backend := &Backend{
-				level:  c.Level,
-				log:    stdlog.New(NewMultiWriter(file), "", c.Flags),
-				config: c,
-			}
-			runtime.SetFinalizer(backend, func(v any) {
-				b := v.(*Backend)
-				mw := b.log.Writer().(*MultiWriter)
-				_ = (*mw.writers.Load())[0].(*os.File).Close()
-			})
-			return backend
+		if c.Filename == "" {
+			return nil, fmt.Errorf("missing log filename")
+		}
+		file, err := os.OpenFile(c.Filename, fileFlags, c.FileMode)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open logfile %s: %w", c.Filename, err)
 		}
+		return &Backend{
+			level:  NewLevelVar(c.Level),
+			log:    stdlog.New(newOwnedMultiWriter(file), "", c.LogFlags()),
+			config: c,
+			json:   c.IsJSON(),
+		}, nil
 	case "syslog":
-		return NewSyslog(c)
+		return newSyslog(c)
 	case "stdout":
 		return &Backend{
-			level:    c.Level,
-			log:      stdlog.New(NewMultiWriter(os.Stdout), "", c.Flags),
+			level:    NewLevelVar(c.Level),
+			log:      stdlog.New(NewMultiWriter(os.Stdout), "", c.LogFlags()),
 			config:   c,
-			usecolor: !color.NoColor,
-		}
+			usecolor: !color.NoColor && !c.NoColor && !c.IsJSON(),
+			json:     c.IsJSON(),
+		}, nil
 	case "stderr":
 		return &Backend{
-			level:    c.Level,
-			log:      stdlog.New(NewMultiWriter(os.Stderr), "", c.Flags),
+			level:    NewLevelVar(c.Level),
+			log:      stdlog.New(NewMultiWriter(os.Stderr), "", c.LogFlags()),
 			config:   c,
-			usecolor: !color.NoColor,
-		}
+			usecolor: !color.NoColor && !c.NoColor && !c.IsJSON(),
+			json:     c.IsJSON(),
+		}, nil
 	default:
-		stdlog.Fatalln("FATAL: Invalid log backend", c.Backend)
+		return nil, fmt.Errorf("invalid log backend %q", c.Backend)
 	}
-	return nil
 }
 
 func (x Backend) Clone(tag string) Logger {
 	b := &Backend{
-		level:    x.level,
+		level:    x.level.Child(),
 		log:      x.log,
 		tag:      x.tag,
-		sampler:  x.sampler.Clone(),
+		samplers: x.samplers.Clone(),
+		config:   x.config,
 		usecolor: x.usecolor,
+		fields:   x.fields,
+		tracer:   x.tracer,
+		hooks:    x.hooks,
+		redactor: x.redactor,
+		dedup:    x.dedup,
+		json:     x.json,
+	}
+	b.appendTag(tag)
+	if x.config != nil {
+		if lvl, ok := matchLevel(x.config.Levels, strings.TrimSpace(tag)); ok {
+			b.level.Set(lvl)
+		}
 	}
 	if x.reg != nil {
 		x.reg.Add(tag, b)
 	}
-	return b.WithTag(tag)
+	return b
+}
+
+// derive returns an independent copy of x. The copy inherits the level
+// from x until its own level is set and shares the sampler state of x, so
+// request-scoped loggers draw from the same budget. Only Clone starts a
+// new sampler state.
+func (x Backend) derive() *Backend {
+	b := x
+	b.level = x.level.Child()
+	return &b
 }
 
-func (x *Backend) WithTag(tag string) Logger {
+func (x *Backend) appendTag(tag string) {
 	tag = strings.TrimSpace(tag)
 	if tag != "" {
 		x.tag += "[" + tag + "] "
 	}
+}
+
+// WithTag returns a derived logger with tag appended to the current tag.
+func (x Backend) WithTag(tag string) Logger {
+	b := x.derive()
+	b.appendTag(tag)
+	return b
+}
+
+// WithRegistry returns a derived logger that registers its clones with r.
+func (x Backend) WithRegistry(r *Registry) Logger {
+	b := x.derive()
+	b.reg = r
+	return b
+}
+
+// WithLevelVar returns a derived logger that shares level v.
+func (x Backend) WithLevelVar(v *LevelVar) Logger {
+	b := x.derive()
+	if v != nil {
+		b.level = v
+	}
+	return b
+}
+
+// WithSampler returns a derived logger that uses sampler s for all levels
+// from trace to error. Fatal and panic messages are never sampled.
+func (x Backend) WithSampler(s Sampling) Logger {
+	b := x.derive()
+	b.samplers.Set(s)
+	return b
+}
+
+// WithLevelSampler returns a derived logger that uses sampler s for the
+// listed levels only. A nil sampler disables sampling for these levels.
+// To sample trace, debug and info while always passing warnings and errors
+// use WithLevelSampler(s, LevelTrace, LevelDebug, LevelInfo).
+func (x Backend) WithLevelSampler(s Sampling, levels ...Level) Logger {
+	b := x.derive()
+	b.samplers.Set(s, levels...)
+	return b
+}
+
+// WithColor returns a derived logger with colored output enabled or
+// disabled. Global color settings remain untouched.
+func (x Backend) WithColor(c bool) Logger {
+	b := x.derive()
+	b.usecolor = c
+	return b
+}
+
+// WithFlags returns a derived logger that writes to the same output
+// using flags f.
+func (x Backend) WithFlags(f int) Logger {
+	b := x.derive()
+	b.log = stdlog.New(x.log.Writer(), x.log.Prefix(), f)
+	return b
+}
+
+// WithFields returns a derived logger that attaches fields to every entry.
+func (x Backend) WithFields(fields ...Field) Logger {
+	b := x.derive()
+	if len(fields) > 0 {
+		b.fields = make([]Field, 0, len(x.fields)+len(fields))
+		b.fields = append(b.fields, x.fields...)
+		b.fields = append(b.fields, fields...)
+	}
+	return b
+}
+
+// WithContext returns a derived logger that attaches all request-scoped
+// fields stored in ctx and trace ids found by the trace extractor to
+// every entry.
+func (x Backend) WithContext(ctx context.Context) Logger {
+	b := x.derive()
+	b.fields = x.contextFields(ctx)
+	return b
+}
+
+// contextFields returns the fields of x merged with the request-scoped
+// fields and trace ids found in ctx. Keys x already carries, e.g. from a
+// logger stored in ctx that was derived with the same context, take the
+// value from ctx instead of being repeated.
+func (x Backend) contextFields(ctx context.Context) []Field {
+	fields := FieldsFromContext(ctx)
+	tracer := x.tracer
+	if tracer == nil {
+		tracer = DefaultTraceExtractor
+	}
+	if tracer != nil {
+		if traceID, spanID := tracer(ctx); traceID != "" {
+			fields = append(fields[:len(fields):len(fields)], Field{FieldTraceID, traceID})
+			if spanID != "" {
+				fields = append(fields, Field{FieldSpanID, spanID})
+			}
+		}
+	}
+	if len(fields) == 0 {
+		return x.fields
+	}
+	all := make([]Field, 0, len(x.fields)+len(fields))
+	all = append(all, x.fields...)
+next:
+	for _, f := range fields {
+		for i := range all {
+			if all[i].Key == f.Key {
+				all[i].Value = f.Value
+				continue next
+			}
+		}
+		all = append(all, f)
+	}
+	return all
+}
+
+// WithHooks returns a derived logger that runs hooks after the hooks
+// inherited from x.
+func (x Backend) WithHooks(hooks ...Hook) Logger {
+	b := x.derive()
+	if len(hooks) > 0 {
+		b.hooks = make([]Hook, 0, len(x.hooks)+len(hooks))
+		b.hooks = append(b.hooks, x.hooks...)
+		b.hooks = append(b.hooks, hooks...)
+	}
+	return b
+}
+
+// WithRedactor returns a derived logger that masks secrets using r after
+// all hooks have run.
+func (x Backend) WithRedactor(r *Redactor) Logger {
+	b := x.derive()
+	b.redactor = r
+	return b
+}
+
+// WithDeduplicator returns a derived logger that collapses repeated
+// entries using d.
+func (x Backend) WithDeduplicator(d *Deduplicator) Logger {
+	b := x.derive()
+	b.dedup = d
+	return b
+}
+
+// WithTraceExtractor returns a derived logger that uses fn to find trace
+// ids in contexts passed to WithContext.
+func (x Backend) WithTraceExtractor(fn TraceExtractor) Logger {
+	b := x.derive()
+	b.tracer = fn
+	return b
+}
+
+// SetTag appends tag to the current tag of x in place. This is what
+// WithTag did before it returned derived loggers.
+func (x *Backend) SetTag(tag string) Logger {
+	x.appendTag(tag)
 	return x
 }
 
-func (x *Backend) WithRegistry(r *Registry) Logger {
+// SetRegistry sets the registry of x in place.
+func (x *Backend) SetRegistry(r *Registry) Logger {
 	x.reg = r
 	return x
 }
 
-func (x *Backend) WithSampler(s *Sampler) Logger {
-	x.sampler = s
+// SetLevelVar makes x use level v in place.
+func (x *Backend) SetLevelVar(v *LevelVar) Logger {
+	if v != nil {
+		x.level = v
+	}
 	return x
 }
 
-func (x *Backend) WithColor(b bool) Logger {
-	x.usecolor = b
-	color.NoColor = !b
+// SetSampler sets the sampler of x for all levels in place.
+func (x *Backend) SetSampler(s Sampling) Logger {
+	x.samplers = samplerSet{}
+	x.samplers.Set(s)
 	return x
 }
 
-func (x Backend) IsColor() bool {
-	return x.usecolor
+// SetColor enables or disables colored output for x and globally for
+// all users of the color package.
+func (x *Backend) SetColor(c bool) Logger {
+	x.usecolor = c
+	color.NoColor = !c
+	return x
 }
 
-func (x *Backend) WithFlags(f int) Logger {
+// SetFlags changes the flags of the underlying standard logger which is
+// shared with all clones of x.
+func (x *Backend) SetFlags(f int) Logger {
 	x.log.SetFlags(f)
 	return x
 }
 
+// IsJSON reports whether x writes entries as JSON objects.
+func (x Backend) IsJSON() bool {
+	return x.json
+}
+
+func (x Backend) IsColor() bool {
+	return x.usecolor
+}
+
 func (x *Backend) Attach(w io.Writer) {
 	x.log.Writer().(*MultiWriter).Add(w)
 	x.usecolor = false
@@ -152,16 +381,13 @@ func (x Backend) Detach(w io.Writer) {
 }
 
 func (x Backend) NewWriter(l Level) io.Writer {
-	if x.level > l {
+	if x.level.Level() > l {
 		return io.Discard
 	}
-	writer := &Backend{
-		level:    l,
-		log:      x.log,
-		tag:      x.tag,
-		config:   x.config,
-		usecolor: x.usecolor,
-	}
+	// keep hooks, redactor and deduplicator so redirected output
+	// passes the same pipeline as regular entries
+	writer := x.derive()
+	writer.level = NewLevelVar(l)
 	return writer
 }
 
@@ -169,10 +395,10 @@ func (x Backend) Write(p []byte) (n int, err error) {
 	if l := len(p); l == 0 {
 		return 0, nil
 	} else if p[l-1] == '\n' {
-		x.output(x.level, string(p[:l-1]))
+		x.output(x.level.Level(), string(p[:l-1]))
 		return l - 1, nil
 	} else {
-		x.output(x.level, string(p))
+		x.output(x.level.Level(), string(p))
 		return l, nil
 	}
 }
@@ -182,13 +408,15 @@ func (x Backend) Logger() *stdlog.Logger {
 }
 
 func (x Backend) Level() Level {
+	return x.level.Level()
+}
+
+func (x Backend) LevelVar() *LevelVar {
 	return x.level
 }
 
 func (x *Backend) SetLevel(l Level) Logger {
-	if l != LevelInvalid {
-		x.level = l
-	}
+	x.level.Set(l)
 	return x
 }
 
@@ -199,61 +427,63 @@ func (x *Backend) SetLevelString(s string) Logger {
 func (x Backend) Noop(...any) {}
 
 func (x Backend) Error(v ...any) {
-	if !x.shouldLog(LevelError) {
+	if !x.shouldLog(LevelError, "") {
 		return
 	}
 	x.output(LevelError, v...)
 }
 
 func (x Backend) Errorf(f string, v ...any) {
-	if !x.shouldLog(LevelError) {
+	if !x.shouldLog(LevelError, f) {
 		return
 	}
 	x.outputf(LevelError, f, v...)
 }
 
 func (x Backend) Warn(v ...any) {
-	if !x.shouldLog(LevelWarn) {
+	if !x.shouldLog(LevelWarn, "") {
 		return
 	}
 	x.output(LevelWarn, v...)
 }
 
 func (x Backend) Warnf(f string, v ...any) {
-	if !x.shouldLog(LevelWarn) {
+	if !x.shouldLog(LevelWarn, f) {
 		return
 	}
 	x.outputf(LevelWarn, f, v...)
 }
 
 func (x Backend) Info(v ...any) {
-	if !x.shouldLog(LevelInfo) {
+	if !x.shouldLog(LevelInfo, "") {
 		return
 	}
 	x.output(LevelInfo, v...)
 }
 
 func (x Backend) Infof(f string, v ...any) {
-	if !x.shouldLog(LevelInfo) {
+	if !x.shouldLog(LevelInfo, f) {
 		return
 	}
 	x.outputf(LevelInfo, f, v...)
 }
 
 func (x Backend) Debug(v ...any) {
-	if !x.shouldLog(LevelDebug) {
+	if !x.shouldLog(LevelDebug, "") {
 		return
 	}
 	x.output(LevelDebug, v...)
 }
 
 func (x Backend) Debugf(f string, v ...any) {
-	if !x.shouldLog(LevelDebug) {
+	if !x.shouldLog(LevelDebug, f) {
 		return
 	}
 	x.outputf(LevelDebug, f, v...)
 }
 
+// Fatal logs v with a stack trace and exits the process. Fatal messages
+// bypass level checks and sampling.
 func (x Backend) Fatal(v ...any) {
 	x.output(LevelFatal, v...)
 	x.stackTrace(LevelFatal, 3)
@@ -261,6 +491,8 @@ func (x Backend) Fatal(v ...any) {
 	os.Exit(1)
 }
 
+// Fatalf logs a formatted message with a stack trace and exits the process.
+// Fatal messages bypass level checks and sampling.
 func (x Backend) Fatalf(f string, v ...any) {
 	x.outputf(LevelFatal, f, v...)
 	x.stackTrace(LevelFatal, 3)
@@ -268,30 +500,118 @@ func (x Backend) Fatalf(f string, v ...any) {
 	os.Exit(1)
 }
 
+// Panic logs v and panics. Panic messages bypass level checks and sampling.
 func (x Backend) Panic(v ...any) {
 	x.output(LevelFatal, v...)
 	panic("abort")
 }
 
+// Panicf logs a formatted message and panics. Panic messages bypass level
+// checks and sampling.
 func (x Backend) Panicf(f string, v ...any) {
 	x.outputf(LevelFatal, f, v...)
 	panic("abort")
 }
 
 func (x Backend) Trace(v ...any) {
-	if !x.shouldLog(LevelTrace) {
+	if !x.shouldLog(LevelTrace, "") {
 		return
 	}
 	x.output(LevelTrace, v...)
 }
 
 func (x Backend) Tracef(f string, v ...any) {
-	if !x.shouldLog(LevelTrace) {
+	if !x.shouldLog(LevelTrace, f) {
+		return
+	}
+	x.outputf(LevelTrace, f, v...)
+}
+
+// The Context variants attach request-scoped fields and trace ids from ctx
+// to a single entry. Unlike WithContext they don't derive a logger, so
+// the context is only inspected when the entry passes level and sampling
+// checks.
+
+func (x Backend) TraceContext(ctx context.Context, v ...any) {
+	if !x.shouldLog(LevelTrace, "") {
+		return
+	}
+	x.fields = x.contextFields(ctx)
+	x.output(LevelTrace, v...)
+}
+
+func (x Backend) TracefContext(ctx context.Context, f string, v ...any) {
+	if !x.shouldLog(LevelTrace, f) {
 		return
 	}
+	x.fields = x.contextFields(ctx)
 	x.outputf(LevelTrace, f, v...)
 }
 
+func (x Backend) DebugContext(ctx context.Context, v ...any) {
+	if !x.shouldLog(LevelDebug, "") {
+		return
+	}
+	x.fields = x.contextFields(ctx)
+	x.output(LevelDebug, v...)
+}
+
+func (x Backend) DebugfContext(ctx context.Context, f string, v ...any) {
+	if !x.shouldLog(LevelDebug, f) {
+		return
+	}
+	x.fields = x.contextFields(ctx)
+	x.outputf(LevelDebug, f, v...)
+}
+
+func (x Backend) InfoContext(ctx context.Context, v ...any) {
+	if !x.shouldLog(LevelInfo, "") {
+		return
+	}
+	x.fields = x.contextFields(ctx)
+	x.output(LevelInfo, v...)
+}
+
+func (x Backend) InfofContext(ctx context.Context, f string, v ...any) {
+	if !x.shouldLog(LevelInfo, f) {
+		return
+	}
+	x.fields = x.contextFields(ctx)
+	x.outputf(LevelInfo, f, v...)
+}
+
+func (x Backend) WarnContext(ctx context.Context, v ...any) {
+	if !x.shouldLog(LevelWarn, "") {
+		return
+	}
+	x.fields = x.contextFields(ctx)
+	x.output(LevelWarn, v...)
+}
+
+func (x Backend) WarnfContext(ctx context.Context, f string, v ...any) {
+	if !x.shouldLog(LevelWarn, f) {
+		return
+	}
+	x.fields = x.contextFields(ctx)
+	x.outputf(LevelWarn, f, v...)
+}
+
+func (x Backend) ErrorContext(ctx context.Context, v ...any) {
+	if !x.shouldLog(LevelError, "") {
+		return
+	}
+	x.fields = x.contextFields(ctx)
+	x.output(LevelError, v...)
+}
+
+func (x Backend) ErrorfContext(ctx context.Context, f string, v ...any) {
+	if !x.shouldLog(LevelError, f) {
+		return
+	}
+	x.fields = x.contextFields(ctx)
+	x.outputf(LevelError, f, v...)
+}
+
 func (x Backend) output(lvl Level, v ...any) {
 	if len(v) == 1 {
 		if fn, ok := v[0].(func()); ok {
@@ -302,32 +622,68 @@ func (x Backend) output(lvl Level, v ...any) {
 			v[0] = fn()
 		}
 	}
-	m := append(make([]any, 0, len(v)+2), lvl.Prefix(), x.tag)
-	m = append(m, v...)
-	print := fmt.Sprint
-	if x.usecolor {
-		print = levelColors[lvl].Sprint
-	}
-	_ = x.log.Output(calldepth, print(m...))
+	x.write(lvl, fmt.Sprint(v...))
 }
 
 func (x Backend) outputf(lvl Level, f string, v ...any) {
-	f = strings.Join([]string{"%s%s", f}, "") // prefix tag and level %s
-	m := append(make([]any, 0, len(v)+2), lvl.Prefix(), x.tag)
-	m = append(m, v...)
-	print := fmt.Sprintf
-	if x.usecolor {
-		print = levelColors[lvl].Sprintf
+	x.write(lvl, fmt.Sprintf(f, v...))
+}
+
+func (x Backend) write(lvl Level, msg string) {
+	e := Entry{
+		Level:   lvl,
+		Tag:     x.tag,
+		Message: msg,
+		Fields:  x.fields,
+	}
+	if len(x.hooks) > 0 || x.redactor != nil {
+		e.Fields = slices.Clone(x.fields)
+	}
+	for _, h := range x.hooks {
+		if !h.Fire(&e) {
+			return
+		}
+	}
+	if x.redactor != nil {
+		x.redactor.RedactEntry(&e)
+	}
+	if x.dedup != nil && !x.dedup.check(&e, x.emit) {
+		return
 	}
-	_ = x.log.Output(calldepth, print(f, m...))
+	x.emit(&e)
 }
 
-func (x Backend) shouldLog(lvl Level) bool {
-	if x.level > lvl {
+func (x Backend) emit(e *Entry) {
+	if x.json {
+		_ = x.log.Output(calldepth, formatJSON(e))
+		return
+	}
+	var b strings.Builder
+	b.WriteString(e.Level.Prefix())
+	b.WriteString(e.Tag)
+	b.WriteString(e.Message)
+	appendFields(&b, e.Fields)
+	line := b.String()
+	if x.usecolor && e.Level < LevelOff {
+		line = levelColors[e.Level].Sprint(line)
+	}
+	_ = x.log.Output(calldepth, line)
+}
+
+func (x Backend) shouldLog(lvl Level, f string) bool {
+	if x.level.Level() > lvl {
 		return false
 	}
-	if x.sampler != nil {
-		return x.sampler.Sample()
+	if lvl < LevelFatal && x.samplers[lvl] != nil {
+		s := x.samplers[lvl]
+		if !sample(s, f) {
+			return false
+		}
+		if r, ok := s.(suppressionReporter); ok {
+			if n, d := r.suppressed(); n > 0 {
+				x.write(lvl, fmt.Sprintf("suppressed %d %s in last %s", n, pluralize("message", int64(n)), roundDuration(d)))
+			}
+		}
 	}
 	return true
 }
@@ -0,0 +1,57 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestDerivedLoggerKeepsFileOpen(t *testing.T) {
+	c := NewConfig()
+	c.Backend = "file"
+	c.Filename = filepath.Join(t.TempDir(), "test.log")
+	b, err := newBackend(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := b.WithTag("app")
+	b = nil
+	l.Info("before gc")
+	runtime.GC()
+	runtime.GC()
+	l.Info("after gc")
+
+	buf, err := os.ReadFile(c.Filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"[app] before gc", "[app] after gc"} {
+		if !strings.Contains(string(buf), want) {
+			t.Errorf("log file misses %q:\n%s", want, buf)
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	b, err := newBackend(NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := b.Clone("p2p").(*Backend)
+	d := c.WithTag("peer").(*Backend)
+	if c.tag != "[p2p] " || d.tag != "[p2p] [peer] " {
+		t.Errorf("WithTag: parent %q, derived %q", c.tag, d.tag)
+	}
+	c.SetTag("conn")
+	if c.tag != "[p2p] [conn] " {
+		t.Errorf("SetTag = %q, want appended tag", c.tag)
+	}
+	if d.tag != "[p2p] [peer] " {
+		t.Errorf("SetTag changed derived logger tag to %q", d.tag)
+	}
+}
@@ -4,13 +4,22 @@
 package log
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	stdlog "log"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/BurntSushi/toml"
 	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
 )
 
 var defaultFlags int = stdlog.Ldate | stdlog.Ltime | stdlog.Lmicroseconds | stdlog.LUTC
@@ -26,6 +35,14 @@ var levelColors = [...]*color.Color{
 	color.New(ColorFatal),
 }
 
+func init() {
+	// color use is decided per logger, so level colors must not depend
+	// on the global color.NoColor setting
+	for _, c := range levelColors {
+		c.EnableColor()
+	}
+}
+
 func ParseLevel(s string) Level {
 	switch strings.ToLower(s) {
 	case "trace":
@@ -89,23 +106,46 @@ func (l *Level) UnmarshalText(data []byte) error {
 }
 
 type Config struct {
-	Level            Level         `json:"level"`
-	Flags            int           `json:"flags"`
-	Backend          string        `json:"backend"`
-	Addr             string        `json:"addr"`
-	Facility         string        `json:"facility"`
-	Ident            string        `json:"ident"`
-	Filename         string        `json:"filename"`
-	FileMode         os.FileMode   `json:"filemode"`
-	ProgressInterval time.Duration `json:"progress"`
-	NoColor          bool          `json:"nocolor"`
+	Level            Level            `json:"level" yaml:"level" toml:"level"`
+	Flags            int              `json:"flags" yaml:"flags" toml:"flags"`
+	Backend          string           `json:"backend" yaml:"backend" toml:"backend"`
+	Format           string           `json:"format" yaml:"format" toml:"format"`
+	Addr             string           `json:"addr" yaml:"addr" toml:"addr"`
+	Facility         string           `json:"facility" yaml:"facility" toml:"facility"`
+	Ident            string           `json:"ident" yaml:"ident" toml:"ident"`
+	Filename         string           `json:"filename" yaml:"filename" toml:"filename"`
+	FileMode         os.FileMode      `json:"filemode" yaml:"filemode" toml:"filemode"`
+	ProgressInterval time.Duration    `json:"progress" yaml:"progress" toml:"progress"`
+	NoColor          bool             `json:"nocolor" yaml:"nocolor" toml:"nocolor"`
+	Levels           map[string]Level `json:"levels" yaml:"levels" toml:"levels"`
 }
 
+// DefaultEnvPrefix is the prefix of environment variables read by NewConfig.
+const DefaultEnvPrefix = "LOG"
+
 func NewConfig() *Config {
-	c := &Config{
+	c := defaultConfig()
+	c.ParseEnv()
+	return c
+}
+
+// NewConfigPrefix returns a default config with settings from environment
+// variables that start with prefix, e.g. MYAPP_LOG_ for MYAPP_LOG_LEVEL.
+// Unlike NewConfig, variables with the default prefix are ignored.
+func NewConfigPrefix(prefix string) (*Config, error) {
+	c := defaultConfig()
+	if err := c.ParseEnvPrefix(prefix); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func defaultConfig() *Config {
+	return &Config{
 		Level:            LevelInfo,
 		Flags:            defaultFlags,
 		Backend:          "stdout", // stdout, stderr, syslog, file
+		Format:           "text",   // text, json
 		Addr:             "",
 		Facility:         "local0",
 		Ident:            "logfile",
@@ -113,8 +153,6 @@ func NewConfig() *Config {
 		FileMode:         0600,
 		ProgressInterval: defaultProgressInterval,
 	}
-	c.ParseEnv()
-	return c
 }
 
 func ParseFlags(flags string) int {
@@ -141,12 +179,274 @@ func ParseFlags(flags string) int {
 	return cflags
 }
 
+// ParseEnv reads settings from environment variables with the default
+// prefix, e.g. LOGLEVEL. Invalid values are ignored.
 func (cfg *Config) ParseEnv() {
-	cfg.Flags = ParseFlags(os.Getenv("LOGFLAGS"))
-	cfg.Level = ParseLevel(os.Getenv("LOGLEVEL"))
-	if cfg.Level == LevelInvalid {
-		cfg.Level = LevelInfo
+	_ = cfg.ParseEnvPrefix(DefaultEnvPrefix)
+}
+
+// ParseEnvPrefix reads settings from environment variables named prefix
+// followed by LEVEL, LEVELS (tag=level list), FLAGS, BACKEND, FORMAT,
+// ADDR, FACILITY, IDENT, FILENAME, FILEMODE (octal), PROGRESS (duration)
+// and COLOR (bool).
+// Only variables that are set override the current values. All invalid
+// values are reported together.
+func (cfg *Config) ParseEnvPrefix(prefix string) error {
+	var errs []error
+	env := func(name string) (string, bool) {
+		return os.LookupEnv(prefix + name)
+	}
+	if v, ok := env("LEVEL"); ok {
+		if err := cfg.Level.UnmarshalText([]byte(v)); err != nil {
+			errs = append(errs, fmt.Errorf("%sLEVEL: %w", prefix, err))
+		}
+	}
+	if v, ok := env("LEVELS"); ok {
+		if m, err := ParseLevelMap(v); err != nil {
+			errs = append(errs, fmt.Errorf("%sLEVELS: %w", prefix, err))
+		} else {
+			cfg.Levels = m
+		}
+	}
+	if v, ok := env("FLAGS"); ok {
+		cfg.Flags = ParseFlags(v)
+	}
+	for name, dst := range map[string]*string{
+		"BACKEND":  &cfg.Backend,
+		"FORMAT":   &cfg.Format,
+		"ADDR":     &cfg.Addr,
+		"FACILITY": &cfg.Facility,
+		"IDENT":    &cfg.Ident,
+		"FILENAME": &cfg.Filename,
+	} {
+		if v, ok := env(name); ok {
+			*dst = v
+		}
+	}
+	if v, ok := env("FILEMODE"); ok {
+		if m, err := strconv.ParseUint(v, 8, 32); err != nil {
+			errs = append(errs, fmt.Errorf("%sFILEMODE: invalid file mode %q", prefix, v))
+		} else {
+			cfg.FileMode = os.FileMode(m)
+		}
 	}
+	if v, ok := env("PROGRESS"); ok {
+		if d, err := time.ParseDuration(v); err != nil {
+			errs = append(errs, fmt.Errorf("%sPROGRESS: %w", prefix, err))
+		} else {
+			cfg.ProgressInterval = d
+		}
+	}
+	if v, ok := env("COLOR"); ok {
+		if c, err := parseBool(v); err != nil {
+			errs = append(errs, fmt.Errorf("%sCOLOR: %w", prefix, err))
+		} else {
+			cfg.NoColor = !c
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// jsonDuration decodes a duration from a string like "5s" or from an
+// integer number of nanoseconds.
+type jsonDuration time.Duration
+
+func (d *jsonDuration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		var n int64
+		if err := json.Unmarshal(data, &n); err != nil {
+			return fmt.Errorf("invalid duration %s", data)
+		}
+		*d = jsonDuration(n)
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = jsonDuration(v)
+	return nil
+}
+
+// parseBool accepts the values of strconv.ParseBool as well as on/off
+// and yes/no.
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "on", "yes":
+		return true, nil
+	case "off", "no":
+		return false, nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("invalid boolean %q", s)
+	}
+	return b, nil
+}
+
+// IsJSON reports whether entries are written as JSON objects.
+func (cfg *Config) IsJSON() bool {
+	return strings.EqualFold(cfg.Format, "json")
 }
 
-func (cfg *Config) Check() error { return nil }
+// LogFlags returns the flags for the standard logger. JSON entries carry
+// their own timestamp, so no flags are used.
+func (cfg *Config) LogFlags() int {
+	if cfg.IsJSON() {
+		return 0
+	}
+	return cfg.Flags
+}
+
+// Check validates the configuration and reports all problems at once.
+// Syslog settings are only checked for the syslog backend and the file
+// location only for the file backend.
+func (cfg *Config) Check() error {
+	var errs []error
+	if cfg.Level > LevelOff {
+		errs = append(errs, fmt.Errorf("invalid log level %d", cfg.Level))
+	}
+	switch strings.ToLower(cfg.Format) {
+	case "", "text", "json":
+	default:
+		errs = append(errs, fmt.Errorf("invalid log format %q", cfg.Format))
+	}
+	for tag, lvl := range cfg.Levels {
+		if lvl > LevelOff {
+			errs = append(errs, fmt.Errorf("invalid log level %d for tag %q", lvl, tag))
+		}
+	}
+	if cfg.ProgressInterval < 0 {
+		errs = append(errs, fmt.Errorf("invalid progress interval %s", cfg.ProgressInterval))
+	}
+	switch strings.ToLower(cfg.Backend) {
+	case "stdout", "stderr":
+	case "syslog":
+		if cfg.Addr != "" {
+			if err := checkSyslogAddr(cfg.Addr); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if !slices.Contains(syslogFacilities[:], strings.ToLower(cfg.Facility)) {
+			errs = append(errs, fmt.Errorf("invalid syslog facility %q", cfg.Facility))
+		}
+	case "file":
+		if cfg.Filename == "" {
+			errs = append(errs, errors.New("missing log filename"))
+		} else if err := checkWritable(cfg.Filename); err != nil {
+			errs = append(errs, err)
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid log backend %q", cfg.Backend))
+	}
+	return errors.Join(errs...)
+}
+
+var syslogFacilities = [...]string{
+	"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news",
+	"uucp", "cron", "authpriv", "ftp", "local0", "local1", "local2",
+	"local3", "local4", "local5", "local6", "local7",
+}
+
+// checkSyslogAddr checks addr is of form protocol://path.
+func checkSyslogAddr(addr string) error {
+	network, raddr, ok := strings.Cut(addr, "://")
+	if !ok || raddr == "" {
+		return fmt.Errorf("invalid syslog address %q: must be of form protocol://path (e.g. unix:///dev/log)", addr)
+	}
+	switch network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6", "unix", "unixgram":
+		return nil
+	default:
+		return fmt.Errorf("invalid syslog address %q: unsupported protocol %q", addr, network)
+	}
+}
+
+// checkWritable checks that name can be opened for appending or created.
+func checkWritable(name string) error {
+	fi, err := os.Stat(name)
+	switch {
+	case err == nil:
+		if fi.IsDir() {
+			return fmt.Errorf("log file %s is a directory", name)
+		}
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			return fmt.Errorf("log file %s is not writable: %w", name, err)
+		}
+		return f.Close()
+	case !errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("log file %s: %w", name, err)
+	}
+	dir := filepath.Dir(name)
+	f, err := os.CreateTemp(dir, ".log-*")
+	if err != nil {
+		return fmt.Errorf("log directory %s is not writable: %w", dir, err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// LoadConfig reads a configuration file and validates it. The format
+// (json, yaml or toml) is derived from the file extension. Settings missing
+// in the file keep the built-in defaults, environment variables are not
+// read. To let the environment override the file, apply it afterwards and
+// check again:
+//
+//	cfg, err := log.LoadConfig("log.yaml")
+//	...
+//	err = errors.Join(cfg.ParseEnvPrefix("MYAPP_LOG_"), cfg.Check())
+func LoadConfig(name string) (*Config, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	cfg := defaultConfig()
+	if err := cfg.Read(f, strings.TrimPrefix(filepath.Ext(name), ".")); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if err := cfg.Check(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return cfg, nil
+}
+
+// Read decodes settings from r into cfg. Supported formats are json, yaml
+// and toml. Unknown keys are rejected.
+func (cfg *Config) Read(r io.Reader, format string) error {
+	switch strings.ToLower(format) {
+	case "json":
+		// accept durations as strings like yaml and toml do
+		type config Config
+		aux := struct {
+			*config
+			ProgressInterval *jsonDuration `json:"progress"`
+		}{
+			config:           (*config)(cfg),
+			ProgressInterval: (*jsonDuration)(&cfg.ProgressInterval),
+		}
+		dec := json.NewDecoder(r)
+		dec.DisallowUnknownFields()
+		return dec.Decode(&aux)
+	case "yaml", "yml":
+		dec := yaml.NewDecoder(r)
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && err != io.EOF {
+			return err
+		}
+		return nil
+	case "toml":
+		md, err := toml.NewDecoder(r).Decode(cfg)
+		if err != nil {
+			return err
+		}
+		if keys := md.Undecoded(); len(keys) > 0 {
+			return fmt.Errorf("unknown config key %q", keys[0].String())
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported config file format %q", format)
+	}
+}
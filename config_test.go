@@ -0,0 +1,202 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigRead(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		in       string
+		progress time.Duration
+		mode     os.FileMode
+		levels   map[string]Level
+		err      string
+	}{
+		{
+			name:     "json",
+			format:   "json",
+			in:       `{"progress": "5s", "filemode": 420, "levels": {"db": "debug", "p2p": "warn"}}`,
+			progress: 5 * time.Second,
+			mode:     0644,
+			levels:   map[string]Level{"db": LevelDebug, "p2p": LevelWarn},
+		},
+		{
+			name:     "json nanoseconds",
+			format:   "json",
+			in:       `{"progress": 2000000000}`,
+			progress: 2 * time.Second,
+			mode:     0600,
+		},
+		{
+			name:     "yaml",
+			format:   "yaml",
+			in:       "progress: 1m30s\nfilemode: 0644\nlevels:\n  db: trace\n",
+			progress: 90 * time.Second,
+			mode:     0644,
+			levels:   map[string]Level{"db": LevelTrace},
+		},
+		{
+			name:     "yml empty",
+			format:   "yml",
+			in:       "",
+			progress: defaultProgressInterval,
+			mode:     0600,
+		},
+		{
+			name:     "toml",
+			format:   "toml",
+			in:       "progress = \"250ms\"\nfilemode = 0o644\n[levels]\ndb = \"error\"\n",
+			progress: 250 * time.Millisecond,
+			mode:     0644,
+			levels:   map[string]Level{"db": LevelError},
+		},
+		{name: "json unknown key", format: "json", in: `{"colour": true}`, err: "colour"},
+		{name: "yaml unknown key", format: "yaml", in: "colour: true\n", err: "colour"},
+		{name: "toml unknown key", format: "toml", in: "colour = true\n", err: "colour"},
+		{name: "json bad duration", format: "json", in: `{"progress": "soon"}`, err: "soon"},
+		{name: "yaml bad level", format: "yaml", in: "levels:\n  db: loud\n", err: "loud"},
+		{name: "toml bad level", format: "toml", in: "level = \"loud\"\n", err: "loud"},
+		{name: "unsupported", format: "ini", in: "", err: "unsupported"},
+	}
+	for _, tt := range tests {
+		cfg := defaultConfig()
+		err := cfg.Read(strings.NewReader(tt.in), tt.format)
+		if tt.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("%s: Read() error = %v, want containing %q", tt.name, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Read() error = %v", tt.name, err)
+			continue
+		}
+		if cfg.ProgressInterval != tt.progress {
+			t.Errorf("%s: progress = %s, want %s", tt.name, cfg.ProgressInterval, tt.progress)
+		}
+		if cfg.FileMode != tt.mode {
+			t.Errorf("%s: filemode = %o, want %o", tt.name, cfg.FileMode, tt.mode)
+		}
+		if len(cfg.Levels) != len(tt.levels) {
+			t.Errorf("%s: levels = %v, want %v", tt.name, cfg.Levels, tt.levels)
+		}
+		for tag, lvl := range tt.levels {
+			if cfg.Levels[tag] != lvl {
+				t.Errorf("%s: levels[%s] = %s, want %s", tt.name, tag, cfg.Levels[tag], lvl)
+			}
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("LOGLEVEL", "error")
+	name := filepath.Join(t.TempDir(), "log.yaml")
+	if err := os.WriteFile(name, []byte("format: json\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// settings missing in the file keep their defaults, env is not read
+	if cfg.Format != "json" || cfg.Level != LevelInfo || cfg.Backend != "stdout" {
+		t.Errorf("LoadConfig() = format %q, level %s, backend %q, want json, info, stdout",
+			cfg.Format, cfg.Level, cfg.Backend)
+	}
+
+	if err := os.WriteFile(name, []byte("format: xml\nbackend: tape\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = LoadConfig(name)
+	if err == nil || !strings.Contains(err.Error(), "xml") || !strings.Contains(err.Error(), "tape") {
+		t.Errorf("LoadConfig() error = %v, want both invalid values", err)
+	}
+}
+
+func TestConfigCheck(t *testing.T) {
+	cfg := defaultConfig()
+	if err := cfg.Check(); err != nil {
+		t.Fatalf("default Check() = %v", err)
+	}
+	cfg.Level = LevelInvalid
+	cfg.Format = "xml"
+	cfg.ProgressInterval = -time.Second
+	cfg.Levels = map[string]Level{"db": LevelInvalid}
+	cfg.Backend = "syslog"
+	cfg.Addr = "localhost:514"
+	cfg.Facility = "local9"
+	err := cfg.Check()
+	if err == nil {
+		t.Fatal("Check() = nil, want error")
+	}
+	for _, want := range []string{"log level", "xml", `tag "db"`, "progress", "localhost:514", "local9"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Check() error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestParseEnvPrefix(t *testing.T) {
+	const prefix = "TEST_ENV_LOG_"
+	t.Setenv(prefix+"LEVEL", "debug")
+	t.Setenv(prefix+"LEVELS", "db=trace,p2p=warn")
+	t.Setenv(prefix+"FORMAT", "json")
+	t.Setenv(prefix+"FILEMODE", "644")
+	t.Setenv(prefix+"PROGRESS", "30s")
+	t.Setenv(prefix+"COLOR", "off")
+
+	cfg := defaultConfig()
+	if err := cfg.ParseEnvPrefix(prefix); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != LevelDebug || cfg.Format != "json" || cfg.FileMode != 0644 ||
+		cfg.ProgressInterval != 30*time.Second || !cfg.NoColor {
+		t.Errorf("ParseEnvPrefix() = %+v", cfg)
+	}
+	if cfg.Levels["db"] != LevelTrace || cfg.Levels["p2p"] != LevelWarn {
+		t.Errorf("levels = %v", cfg.Levels)
+	}
+	// unset variables keep their current values
+	if cfg.Backend != "stdout" || cfg.Facility != "local0" || cfg.Flags != defaultFlags {
+		t.Errorf("unset variables changed config: %+v", cfg)
+	}
+}
+
+func TestParseEnvPrefixErrors(t *testing.T) {
+	const prefix = "TEST_BAD_LOG_"
+	t.Setenv(prefix+"LEVEL", "loud")
+	t.Setenv(prefix+"LEVELS", "db")
+	t.Setenv(prefix+"FILEMODE", "rw-r--r--")
+	t.Setenv(prefix+"PROGRESS", "soon")
+	t.Setenv(prefix+"COLOR", "maybe")
+	t.Setenv(prefix+"FORMAT", "json")
+
+	cfg := defaultConfig()
+	err := cfg.ParseEnvPrefix(prefix)
+	if err == nil {
+		t.Fatal("ParseEnvPrefix() = nil, want error")
+	}
+	for _, name := range []string{"LEVEL:", "LEVELS:", "FILEMODE:", "PROGRESS:", "COLOR:"} {
+		if !strings.Contains(err.Error(), prefix+name) {
+			t.Errorf("error %q does not mention %s", err, prefix+name)
+		}
+	}
+	// valid variables still apply, invalid ones leave defaults
+	want := defaultConfig()
+	if cfg.Format != "json" || cfg.Level != want.Level || cfg.FileMode != want.FileMode ||
+		cfg.ProgressInterval != want.ProgressInterval || cfg.NoColor || cfg.Levels != nil {
+		t.Errorf("ParseEnvPrefix() = %+v", cfg)
+	}
+	if _, err := NewConfigPrefix(prefix); err == nil {
+		t.Error("NewConfigPrefix() = nil error, want error")
+	}
+}
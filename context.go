@@ -0,0 +1,94 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	fieldsKey
+)
+
+// Well-known field names for request-scoped context values.
+const (
+	FieldRequestID = "request_id"
+	FieldTenant    = "tenant"
+)
+
+// Field is a key/value pair attached to log entries.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// NewContext returns a copy of ctx that carries logger l.
+func NewContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, l)
+}
+
+// FromContext returns the logger stored in ctx or the global logger Log
+// when ctx carries none. Fields stored in ctx are attached to the returned
+// logger.
+func FromContext(ctx context.Context) Logger {
+	return loggerFrom(ctx).WithContext(ctx)
+}
+
+// loggerFrom returns the logger stored in ctx or Log.
+func loggerFrom(ctx context.Context) Logger {
+	if l, ok := ctx.Value(loggerKey).(Logger); ok && l != nil {
+		return l
+	}
+	return Log
+}
+
+// ContextWithFields returns a copy of ctx with fields appended to the
+// fields already stored in ctx.
+func ContextWithFields(ctx context.Context, fields ...Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	prev := FieldsFromContext(ctx)
+	all := make([]Field, 0, len(prev)+len(fields))
+	all = append(all, prev...)
+	all = append(all, fields...)
+	return context.WithValue(ctx, fieldsKey, all)
+}
+
+// FieldsFromContext returns all fields stored in ctx.
+func FieldsFromContext(ctx context.Context) []Field {
+	fields, _ := ctx.Value(fieldsKey).([]Field)
+	return fields
+}
+
+// ContextWithRequestID returns a copy of ctx that carries a request id field.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return ContextWithFields(ctx, Field{FieldRequestID, id})
+}
+
+// ContextWithTenant returns a copy of ctx that carries a tenant field.
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	return ContextWithFields(ctx, Field{FieldTenant, tenant})
+}
+
+// appendFields renders fields as space separated key=value pairs. Values
+// that contain spaces, quotes or equal signs are quoted.
+func appendFields(b *strings.Builder, fields []Field) {
+	for _, f := range fields {
+		b.WriteByte(' ')
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		v := fmt.Sprint(f.Value)
+		if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+			v = strconv.Quote(v)
+		}
+		b.WriteString(v)
+	}
+}
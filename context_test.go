@@ -0,0 +1,57 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"bytes"
+	"context"
+	stdlog "log"
+	"strings"
+	"testing"
+)
+
+// newTestLogger returns a logger without timestamps writing to buf.
+func newTestLogger(buf *bytes.Buffer, json bool) *Backend {
+	return &Backend{
+		level: NewLevelVar(LevelTrace),
+		log:   stdlog.New(NewMultiWriter(buf), "", 0),
+		json:  json,
+	}
+}
+
+func TestContextFieldsStoredLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestLogger(&buf, false)
+	ctx := ContextWithRequestID(context.Background(), "r1")
+	ctx = ContextWithTraceparent(ctx, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	// middleware stores a logger derived from the same context
+	ctx = NewContext(ctx, FromContext(NewContext(ctx, l)).WithTag("http"))
+	ctx = ContextWithTenant(ctx, "acme")
+
+	FromContext(ctx).Info("from")
+	InfoContext(ctx, "info")
+	FromContext(ctx).InfoContext(ctx, "both")
+
+	want := "request_id=r1 trace_id=4bf92f3577b34da6a3ce929d0e0e4736 span_id=00f067aa0ba902b7 tenant=acme"
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines:\n%s", len(lines), buf.String())
+	}
+	for i, msg := range []string{"from", "info", "both"} {
+		if exp := "INFO [http] " + msg + " " + want; lines[i] != exp {
+			t.Errorf("line %d = %q, want %q", i, lines[i], exp)
+		}
+	}
+}
+
+func TestContextFieldsOverride(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestLogger(&buf, true).WithFields(Field{FieldRequestID, "old"}, Field{"a", 1})
+	ctx := ContextWithRequestID(context.Background(), "new")
+	l.InfoContext(ctx, "msg")
+	if got := buf.String(); strings.Count(got, FieldRequestID) != 1 || !strings.Contains(got, `"request_id":"new","a":1}`) {
+		t.Errorf("got %s", got)
+	}
+}
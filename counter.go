@@ -0,0 +1,52 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"math/rand/v2"
+	"sync/atomic"
+)
+
+// counterStripes is the number of cache-line padded slots per striped
+// counter. Concurrent writers pick a random slot to avoid contention.
+const counterStripes = 16
+
+type paddedInt64 struct {
+	atomic.Int64
+	_ [56]byte
+}
+
+// stripedCounter is a contention-free counter for many concurrent writers
+// with rare reads.
+type stripedCounter struct {
+	slots [counterStripes]paddedInt64
+}
+
+// stripe returns a random slot index to use for a series of adds.
+func stripe() int {
+	return int(rand.Uint32() % counterStripes)
+}
+
+func (c *stripedCounter) add(i int, n int64) {
+	c.slots[i].Add(n)
+}
+
+// load returns the current total.
+func (c *stripedCounter) load() int64 {
+	var n int64
+	for i := range c.slots {
+		n += c.slots[i].Load()
+	}
+	return n
+}
+
+// take returns the current total and resets the counter without losing
+// concurrent adds.
+func (c *stripedCounter) take() int64 {
+	var n int64
+	for i := range c.slots {
+		n += c.slots[i].Swap(0)
+	}
+	return n
+}
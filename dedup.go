@@ -0,0 +1,98 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultDedupWindow = 10 * time.Second
+
+// Deduplicator collapses consecutive identical entries with the same level,
+// tag, message and fields into a single summary line. A run of duplicates
+// ends when a different entry arrives or when the window since the first
+// entry of the run expires, whichever happens first. Clones share the
+// deduplicator of their parent, so it sees the entire output stream.
+type Deduplicator struct {
+	window time.Duration
+
+	mu    sync.Mutex
+	key   string
+	level Level
+	tag   string
+	first time.Time
+	count int
+	timer *time.Timer
+	emit  func(*Entry)
+}
+
+// NewDeduplicator returns a deduplicator that collapses duplicates within
+// window. A zero window uses a default of 10 seconds.
+func NewDeduplicator(window time.Duration) *Deduplicator {
+	if window <= 0 {
+		window = defaultDedupWindow
+	}
+	return &Deduplicator{window: window}
+}
+
+// Flush writes a pending summary line, if any, and ends the current run.
+func (d *Deduplicator) Flush() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.flushLocked()
+	d.key = ""
+}
+
+// check reports whether e should be written. Duplicates are counted and
+// later summarized through emit.
+func (d *Deduplicator) check(e *Entry, emit func(*Entry)) bool {
+	key := entryKey(e)
+	now := time.Now()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if key == d.key && now.Sub(d.first) < d.window {
+		d.count++
+		d.emit = emit
+		if d.timer == nil {
+			d.timer = time.AfterFunc(d.window-now.Sub(d.first), d.Flush)
+		}
+		return false
+	}
+	d.flushLocked()
+	d.key = key
+	d.level = e.Level
+	d.tag = e.Tag
+	d.first = now
+	return true
+}
+
+func (d *Deduplicator) flushLocked() {
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	if d.count == 0 {
+		return
+	}
+	d.emit(&Entry{
+		Level:   d.level,
+		Tag:     d.tag,
+		Message: fmt.Sprintf("last message repeated %d %s", d.count, pluralize("time", int64(d.count))),
+	})
+	d.count = 0
+	d.emit = nil
+}
+
+func entryKey(e *Entry) string {
+	var b strings.Builder
+	b.WriteByte(byte(e.Level))
+	b.WriteString(e.Tag)
+	b.WriteString(e.Message)
+	appendFields(&b, e.Fields)
+	return b.String()
+}
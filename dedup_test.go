@@ -0,0 +1,107 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"bytes"
+	stdlog "log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a buffer that is safe to write from timer goroutines.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	s := strings.TrimSpace(b.buf.String())
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+func newDedupLogger(window time.Duration) (Logger, *Deduplicator, *syncBuffer) {
+	buf := &syncBuffer{}
+	d := NewDeduplicator(window)
+	l := &Backend{
+		level: NewLevelVar(LevelTrace),
+		log:   stdlog.New(NewMultiWriter(buf), "", 0),
+	}
+	return l.WithDeduplicator(d), d, buf
+}
+
+func expectLines(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got lines\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestDeduplicatorRunEndedByDifferentEntry(t *testing.T) {
+	l, _, buf := newDedupLogger(time.Minute)
+	l.Info("a")
+	l.Info("a")
+	l.Info("a")
+	l.Info("b")
+	l.Warn("b")
+	l.WithFields(Field{"k", 1}).Warn("b")
+	l.WithFields(Field{"k", 1}).Warn("b")
+	l.Info("c")
+	expectLines(t, buf.lines(),
+		"INFO a",
+		"INFO last message repeated 2 times",
+		"INFO b",
+		"WARN b",
+		"WARN b k=1",
+		"WARN last message repeated 1 time",
+		"INFO c",
+	)
+}
+
+func TestDeduplicatorWindowExpiry(t *testing.T) {
+	l, _, buf := newDedupLogger(20 * time.Millisecond)
+	l.Error("a")
+	l.Error("a")
+	l.Error("a")
+	expectLines(t, buf.lines(), "ERRO a")
+
+	// the timer writes the summary without further entries
+	deadline := time.Now().Add(time.Second)
+	for len(buf.lines()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	expectLines(t, buf.lines(), "ERRO a", "ERRO last message repeated 2 times")
+
+	// an identical entry after the window starts a new run
+	l.Error("a")
+	l.Error("a")
+	expectLines(t, buf.lines(), "ERRO a", "ERRO last message repeated 2 times", "ERRO a")
+}
+
+func TestDeduplicatorFlush(t *testing.T) {
+	l, d, buf := newDedupLogger(time.Minute)
+	l.Clone("x").Debug("a")
+	l.Clone("x").Debug("a")
+	d.Flush()
+	d.Flush()
+	l.Clone("x").Debug("a")
+	expectLines(t, buf.lines(),
+		"DEBG [x] a",
+		"DEBG [x] last message repeated 1 time",
+		"DEBG [x] a",
+	)
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"flag"
+	"fmt"
+	stdlog "log"
+	"slices"
+	"strings"
+)
+
+// RegisterFlags binds config settings to command line flags in fs. Flag
+// names start with prefix, e.g. "log." registers -log.level. Current values
+// of cfg are used as defaults, so call it after NewConfig or LoadConfig.
+//
+// Per-tag levels are set with -levels as comma separated tag=level
+// directives that may contain a wildcard, e.g. "db=debug,api.*=warn". They
+// apply to loggers cloned from a logger created with this config.
+func (cfg *Config) RegisterFlags(fs *flag.FlagSet, prefix string) {
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+	fs.Var(&cfg.Level, prefix+"level", "log level (trace, debug, info, warn, error, fatal, off)")
+	fs.Var(&levelMapValue{&cfg.Levels}, prefix+"levels", "per-tag log levels as tag=level[,tag=level...]")
+	fs.Var(&logFlagsValue{&cfg.Flags}, prefix+"flags", "log line flags (date, time, micro, utc, shortfile, longfile)")
+	fs.StringVar(&cfg.Backend, prefix+"backend", cfg.Backend, "log backend (stdout, stderr, syslog, file)")
+	fs.StringVar(&cfg.Format, prefix+"format", cfg.Format, "log format (text, json)")
+	fs.StringVar(&cfg.Filename, prefix+"file", cfg.Filename, "log file name for the file backend")
+	fs.StringVar(&cfg.Addr, prefix+"syslog.addr", cfg.Addr, "syslog address as protocol://path, defaults to the local syslog")
+	fs.StringVar(&cfg.Facility, prefix+"syslog.facility", cfg.Facility, "syslog facility")
+	fs.StringVar(&cfg.Ident, prefix+"syslog.ident", cfg.Ident, "syslog ident")
+	fs.Var(&colorValue{&cfg.NoColor}, prefix+"color", "colorize log output on terminals")
+}
+
+// Set implements flag.Value.
+func (l *Level) Set(s string) error {
+	return l.UnmarshalText([]byte(s))
+}
+
+// FormatFlags is the inverse of ParseFlags.
+func FormatFlags(flags int) string {
+	names := make([]string, 0, 6)
+	for _, f := range []struct {
+		flag int
+		name string
+	}{
+		{stdlog.Ldate, "date"},
+		{stdlog.Ltime, "time"},
+		{stdlog.Lmicroseconds, "micro"},
+		{stdlog.LUTC, "utc"},
+		{stdlog.Lshortfile, "shortfile"},
+		{stdlog.Llongfile, "longfile"},
+	} {
+		if flags&f.flag != 0 {
+			names = append(names, f.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// logFlagsValue implements flag.Value for standard logger flags.
+type logFlagsValue struct {
+	p *int
+}
+
+func (v *logFlagsValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	return FormatFlags(*v.p)
+}
+
+func (v *logFlagsValue) Set(s string) error {
+	*v.p = ParseFlags(s)
+	return nil
+}
+
+// colorValue implements a boolean flag that clears NoColor.
+type colorValue struct {
+	p *bool
+}
+
+func (v *colorValue) String() string {
+	// the zero value must differ from the default so that flag
+	// usage shows color is on by default
+	if v.p == nil {
+		return "false"
+	}
+	return fmt.Sprint(!*v.p)
+}
+
+func (v *colorValue) Set(s string) error {
+	c, err := parseBool(s)
+	if err != nil {
+		return err
+	}
+	*v.p = !c
+	return nil
+}
+
+func (v *colorValue) IsBoolFlag() bool { return true }
+
+// levelMapValue implements flag.Value for per-tag level directives. Repeated
+// flags add to the map.
+type levelMapValue struct {
+	p *map[string]Level
+}
+
+func (v *levelMapValue) String() string {
+	if v.p == nil {
+		return ""
+	}
+	list := make([]string, 0, len(*v.p))
+	for tag, lvl := range *v.p {
+		list = append(list, tag+"="+lvl.String())
+	}
+	slices.Sort(list)
+	return strings.Join(list, ",")
+}
+
+func (v *levelMapValue) Set(s string) error {
+	m, err := ParseLevelMap(s)
+	if err != nil {
+		return err
+	}
+	if *v.p == nil {
+		*v.p = make(map[string]Level, len(m))
+	}
+	for tag, lvl := range m {
+		(*v.p)[tag] = lvl
+	}
+	return nil
+}
+
+// ParseLevelMap parses comma separated tag=level directives.
+func ParseLevelMap(s string) (map[string]Level, error) {
+	m := make(map[string]Level)
+	for _, d := range strings.Split(s, ",") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+		tag, val, ok := strings.Cut(d, "=")
+		if !ok || tag == "" {
+			return nil, fmt.Errorf("invalid level directive %q, must be tag=level", d)
+		}
+		var lvl Level
+		if err := lvl.UnmarshalText([]byte(val)); err != nil {
+			return nil, err
+		}
+		m[tag] = lvl
+	}
+	return m, nil
+}
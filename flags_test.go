@@ -0,0 +1,88 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRegisterFlags(t *testing.T) {
+	cfg := defaultConfig()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	cfg.RegisterFlags(fs, "log.")
+	err := fs.Parse([]string{
+		"-log.level", "debug",
+		"-log.levels", "db=trace,api.*=warn",
+		"-log.levels", "db=error",
+		"-log.flags", "time,utc",
+		"-log.backend", "stderr",
+		"-log.color=false",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Level != LevelDebug || cfg.Backend != "stderr" || !cfg.NoColor {
+		t.Errorf("level=%s backend=%s nocolor=%t", cfg.Level, cfg.Backend, cfg.NoColor)
+	}
+	if want := map[string]Level{"db": LevelError, "api.*": LevelWarn}; !reflect.DeepEqual(cfg.Levels, want) {
+		t.Errorf("levels = %v, want %v", cfg.Levels, want)
+	}
+	if got := FormatFlags(cfg.Flags); got != "time,utc" {
+		t.Errorf("flags = %q", got)
+	}
+	if err := fs.Set("log.level", "loud"); err == nil {
+		t.Error("invalid level accepted")
+	}
+}
+
+func TestRegisterFlagsDefaults(t *testing.T) {
+	cfg := defaultConfig()
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	var out strings.Builder
+	fs.SetOutput(&out)
+	cfg.RegisterFlags(fs, "log.")
+	fs.PrintDefaults()
+	for _, want := range []string{
+		"colorize log output on terminals (default true)",
+		"(default info)",
+		"(default date,time,micro,utc)",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("defaults miss %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestParseLevelMap(t *testing.T) {
+	tests := []struct {
+		in   string
+		want map[string]Level
+		err  bool
+	}{
+		{"", map[string]Level{}, false},
+		{"db=debug", map[string]Level{"db": LevelDebug}, false},
+		{" db=debug , api.*=WARN,", map[string]Level{"db": LevelDebug, "api.*": LevelWarn}, false},
+		{"db=info,db=off", map[string]Level{"db": LevelOff}, false},
+		{"db", nil, true},
+		{"=debug", nil, true},
+		{"db=", nil, true},
+		{"db=loud", nil, true},
+		{"db=debug,api", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevelMap(tt.in)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseLevelMap(%q) error = %v, want error %t", tt.in, err, tt.err)
+			continue
+		}
+		if !tt.err && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseLevelMap(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
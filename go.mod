@@ -2,10 +2,14 @@ module github.com/echa/log
 
 go 1.24.0
 
-require github.com/fatih/color v1.18.0
+require (
+	github.com/BurntSushi/toml v1.5.0
+	github.com/fatih/color v1.18.0
+	github.com/mattn/go-isatty v0.0.20
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/mattn/go-colorable v0.1.14 // indirect
-	github.com/mattn/go-isatty v0.0.20 // indirect
 	golang.org/x/sys v0.37.0 // indirect
 )
@@ -0,0 +1,30 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+// Entry is a single log entry as seen by hooks before it is written.
+type Entry struct {
+	// Level is the entry's log level.
+	Level Level
+	// Tag is the rendered tag prefix of the logger, e.g. "[p2p] ".
+	Tag string
+	// Message is the formatted log message without level and tag.
+	Message string
+	// Fields are the key/value pairs attached to the entry. Hooks may
+	// modify this slice freely.
+	Fields []Field
+}
+
+// Hook is called for every entry that passed the level and sampling checks.
+// Hooks can add fields, rewrite the entry or drop it by returning false.
+type Hook interface {
+	Fire(e *Entry) bool
+}
+
+// HookFunc adapts an ordinary function to the Hook interface.
+type HookFunc func(e *Entry) bool
+
+func (f HookFunc) Fire(e *Entry) bool {
+	return f(e)
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Unit selects how progress counter values are formatted.
+type Unit byte
+
+const (
+	// UnitNone prints plain numbers, e.g. 40000.
+	UnitNone Unit = iota
+	// UnitCount prints numbers with thousands separators, e.g. 40,000.
+	UnitCount
+	// UnitSI prints numbers with SI suffixes, e.g. 40k or 1.2M.
+	UnitSI
+	// UnitBytesSI prints byte sizes with decimal prefixes, e.g. 12.9 MB.
+	UnitBytesSI
+	// UnitBytesIEC prints byte sizes with binary prefixes, e.g. 12.3 MiB.
+	UnitBytesIEC
+)
+
+// IsBytes reports whether u formats byte sizes which carry their own
+// unit name.
+func (u Unit) IsBytes() bool {
+	return u == UnitBytesSI || u == UnitBytesIEC
+}
+
+// Format formats n according to unit u.
+func (u Unit) Format(n int64) string {
+	switch u {
+	case UnitCount:
+		return formatThousands(n)
+	case UnitSI:
+		return formatScaled(n, 1000, []string{"", "k", "M", "G", "T", "P", "E"}, "")
+	case UnitBytesSI:
+		return formatScaled(n, 1000, []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}, " ")
+	case UnitBytesIEC:
+		return formatScaled(n, 1024, []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}, " ")
+	default:
+		return strconv.FormatInt(n, 10)
+	}
+}
+
+func formatThousands(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, s = "-", s[1:]
+	}
+	if len(s) <= 3 {
+		return sign + s
+	}
+	var b strings.Builder
+	b.WriteString(sign)
+	head := len(s) % 3
+	if head > 0 {
+		b.WriteString(s[:head])
+	}
+	for i := head; i < len(s); i += 3 {
+		if b.Len() > len(sign) {
+			b.WriteByte(',')
+		}
+		b.WriteString(s[i : i+3])
+	}
+	return b.String()
+}
+
+func formatScaled(n int64, base float64, suffixes []string, sep string) string {
+	v := float64(n)
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	i := 0
+	for v >= base && i < len(suffixes)-1 {
+		v /= base
+		i++
+	}
+	// avoid results like 1000.0 kB after rounding
+	if i > 0 && v >= base-0.05 && i < len(suffixes)-1 {
+		v /= base
+		i++
+	}
+	num := strconv.FormatFloat(v, 'f', 1, 64)
+	if i == 0 {
+		num = strconv.FormatInt(int64(v), 10)
+	}
+	num = strings.TrimSuffix(num, ".0")
+	if suffixes[i] == "" {
+		return sign + num
+	}
+	return sign + num + sep + suffixes[i]
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFormatThousands(t *testing.T) {
+	tests := []struct {
+		in   int64
+		want string
+	}{
+		{0, "0"},
+		{7, "7"},
+		{999, "999"},
+		{1000, "1,000"},
+		{12345, "12,345"},
+		{999_999, "999,999"},
+		{1_000_000, "1,000,000"},
+		{1_234_567, "1,234,567"},
+		{-1, "-1"},
+		{-999, "-999"},
+		{-1000, "-1,000"},
+		{-123_456, "-123,456"},
+		{-999_999, "-999,999"},
+		{math.MaxInt64, "9,223,372,036,854,775,807"},
+		{math.MinInt64, "-9,223,372,036,854,775,808"},
+	}
+	for _, tt := range tests {
+		if got := formatThousands(tt.in); got != tt.want {
+			t.Errorf("formatThousands(%d) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFormatScaled(t *testing.T) {
+	tests := []struct {
+		unit Unit
+		in   int64
+		want string
+	}{
+		{UnitSI, 0, "0"},
+		{UnitSI, 999, "999"},
+		{UnitSI, 1000, "1k"},
+		{UnitSI, 1500, "1.5k"},
+		{UnitSI, 40_000, "40k"},
+		{UnitSI, 999_900, "999.9k"},
+		{UnitSI, 999_949, "999.9k"},
+		{UnitSI, 999_951, "1M"},
+		{UnitSI, 999_999, "1M"},
+		{UnitSI, 1_000_000, "1M"},
+		{UnitSI, 1_234_567, "1.2M"},
+		{UnitSI, 1_250_000, "1.2M"},
+		{UnitSI, 999_999_999, "1G"},
+		{UnitSI, math.MaxInt64, "9.2E"},
+		{UnitSI, -1, "-1"},
+		{UnitSI, -1500, "-1.5k"},
+		{UnitSI, -999_999, "-1M"},
+		{UnitBytesSI, 0, "0 B"},
+		{UnitBytesSI, 999, "999 B"},
+		{UnitBytesSI, 1000, "1 kB"},
+		{UnitBytesSI, 12_900_000, "12.9 MB"},
+		{UnitBytesSI, 999_999, "1 MB"},
+		{UnitBytesSI, -2500, "-2.5 kB"},
+		{UnitBytesIEC, 1023, "1023 B"},
+		{UnitBytesIEC, 1024, "1 KiB"},
+		{UnitBytesIEC, 1536, "1.5 KiB"},
+		{UnitBytesIEC, 1<<20 - 1, "1 MiB"},
+		{UnitBytesIEC, 12_900_000, "12.3 MiB"},
+		{UnitBytesIEC, 1 << 40, "1 TiB"},
+		{UnitBytesIEC, -1 << 30, "-1 GiB"},
+		{UnitBytesIEC, math.MaxInt64, "8 EiB"},
+	}
+	for _, tt := range tests {
+		if got := tt.unit.Format(tt.in); got != tt.want {
+			t.Errorf("Unit(%d).Format(%d) = %q, want %q", tt.unit, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestUnitFormat(t *testing.T) {
+	tests := []struct {
+		unit Unit
+		in   int64
+		want string
+	}{
+		{UnitNone, 40000, "40000"},
+		{UnitNone, -5, "-5"},
+		{UnitCount, 40000, "40,000"},
+		{UnitCount, -40000, "-40,000"},
+	}
+	for _, tt := range tests {
+		if got := tt.unit.Format(tt.in); got != tt.want {
+			t.Errorf("Unit(%d).Format(%d) = %q, want %q", tt.unit, tt.in, got, tt.want)
+		}
+	}
+}
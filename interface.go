@@ -4,6 +4,7 @@
 package log
 
 import (
+	"context"
 	"io"
 	stdlog "log"
 
@@ -52,15 +53,41 @@ type Logger interface {
 	Fatalf(string, ...any)
 	Panic(...any)
 	Panicf(string, ...any)
+	TraceContext(context.Context, ...any)
+	TracefContext(context.Context, string, ...any)
+	DebugContext(context.Context, ...any)
+	DebugfContext(context.Context, string, ...any)
+	InfoContext(context.Context, ...any)
+	InfofContext(context.Context, string, ...any)
+	WarnContext(context.Context, ...any)
+	WarnfContext(context.Context, string, ...any)
+	ErrorContext(context.Context, ...any)
+	ErrorfContext(context.Context, string, ...any)
 	Level() Level
+	LevelVar() *LevelVar
 	IsColor() bool
+	IsJSON() bool
 	SetLevel(Level) Logger
 	SetLevelString(string) Logger
+	SetTag(string) Logger
+	SetRegistry(*Registry) Logger
+	SetLevelVar(*LevelVar) Logger
+	SetSampler(Sampling) Logger
+	SetColor(bool) Logger
+	SetFlags(int) Logger
 	Logger() *stdlog.Logger
 	Clone(string) Logger
 	WithTag(string) Logger
+	WithFields(...Field) Logger
+	WithContext(context.Context) Logger
+	WithHooks(...Hook) Logger
+	WithRedactor(*Redactor) Logger
+	WithDeduplicator(*Deduplicator) Logger
+	WithTraceExtractor(TraceExtractor) Logger
 	WithRegistry(*Registry) Logger
-	WithSampler(*Sampler) Logger
+	WithLevelVar(*LevelVar) Logger
+	WithSampler(Sampling) Logger
+	WithLevelSampler(Sampling, ...Level) Logger
 	WithColor(bool) Logger
 	WithFlags(int) Logger
 	Attach(io.Writer)
@@ -84,3 +111,15 @@ func Panic(v ...any)                 { Log.Panic(v...) }
 func Panicf(s string, v ...any)      { Log.Panicf(s, v...) }
 func SetLevel(l Level) Logger        { Log.SetLevel(l); return Log }
 func SetLevelString(l string) Logger { return SetLevel(ParseLevel(l)) }
+
+// package level forwarders to the logger stored in the context
+func TraceContext(c context.Context, v ...any)            { loggerFrom(c).TraceContext(c, v...) }
+func TracefContext(c context.Context, s string, v ...any) { loggerFrom(c).TracefContext(c, s, v...) }
+func DebugContext(c context.Context, v ...any)            { loggerFrom(c).DebugContext(c, v...) }
+func DebugfContext(c context.Context, s string, v ...any) { loggerFrom(c).DebugfContext(c, s, v...) }
+func InfoContext(c context.Context, v ...any)             { loggerFrom(c).InfoContext(c, v...) }
+func InfofContext(c context.Context, s string, v ...any)  { loggerFrom(c).InfofContext(c, s, v...) }
+func WarnContext(c context.Context, v ...any)             { loggerFrom(c).WarnContext(c, v...) }
+func WarnfContext(c context.Context, s string, v ...any)  { loggerFrom(c).WarnfContext(c, s, v...) }
+func ErrorContext(c context.Context, v ...any)            { loggerFrom(c).ErrorContext(c, v...) }
+func ErrorfContext(c context.Context, s string, v ...any) { loggerFrom(c).ErrorfContext(c, s, v...) }
@@ -0,0 +1,68 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// reservedKeys are written by formatJSON for every entry.
+var reservedKeys = [...]string{"time", "level", "tag", "msg"}
+
+// formatJSON renders e as a single line JSON object with time, level,
+// tag and msg keys followed by all fields in order. Fields that collide
+// with these keys are prefixed with "fields.".
+func formatJSON(e *Entry) string {
+	var b bytes.Buffer
+	b.WriteString(`{"time":`)
+	appendJSON(&b, time.Now().UTC().Format(time.RFC3339Nano))
+	b.WriteString(`,"level":`)
+	appendJSON(&b, e.Level.String())
+	if tag := tagName(e.Tag); tag != "" {
+		b.WriteString(`,"tag":`)
+		appendJSON(&b, tag)
+	}
+	b.WriteString(`,"msg":`)
+	appendJSON(&b, e.Message)
+	for _, f := range e.Fields {
+		b.WriteByte(',')
+		key := f.Key
+		if slices.Contains(reservedKeys[:], key) {
+			key = "fields." + key
+		}
+		appendJSON(&b, key)
+		b.WriteByte(':')
+		appendJSON(&b, f.Value)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func appendJSON(b *bytes.Buffer, v any) {
+	if err, ok := v.(error); ok {
+		v = err.Error()
+	}
+	enc := json.NewEncoder(b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		_ = enc.Encode(fmt.Sprint(v))
+	}
+	// drop the newline added by Encode
+	b.Truncate(b.Len() - 1)
+}
+
+// tagName converts a rendered tag prefix like "[p2p] [peer] " into a
+// dotted name like "p2p.peer".
+func tagName(tag string) string {
+	parts := strings.Fields(tag)
+	for i, p := range parts {
+		parts[i] = strings.TrimSuffix(strings.TrimPrefix(p, "["), "]")
+	}
+	return strings.Join(parts, ".")
+}
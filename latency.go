@@ -0,0 +1,142 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"fmt"
+	"math/bits"
+	"sync/atomic"
+	"time"
+)
+
+// Latencies are recorded into a log-linear histogram with 8 sub-buckets per
+// power of two which bounds memory to 4 KiB and relative error to 12.5%.
+const (
+	latencySubBits = 3
+	latencySub     = 1 << latencySubBits
+	latencyBuckets = (64 - latencySubBits + 1) * latencySub
+)
+
+// latencyHistogram records durations from concurrent writers.
+type latencyHistogram struct {
+	counts [latencyBuckets]atomic.Uint64
+	sum    stripedCounter
+	min    atomic.Int64 // offset by one, zero means unset
+	max    atomic.Int64
+}
+
+// latencyStats is a snapshot of a latency histogram.
+type latencyStats struct {
+	counts [latencyBuckets]uint64
+	n      int64
+	sum    time.Duration
+	min    time.Duration
+	max    time.Duration
+}
+
+func latencyBucket(d time.Duration) int {
+	v := uint64(max(d, 0))
+	if v < latencySub {
+		return int(v)
+	}
+	e := bits.Len64(v) - 1
+	m := (v >> (e - latencySubBits)) & (latencySub - 1)
+	return (e-latencySubBits+1)*latencySub + int(m)
+}
+
+// latencyBound returns the lower bound of bucket i.
+func latencyBound(i int) time.Duration {
+	if i < latencySub {
+		return time.Duration(i)
+	}
+	e := i/latencySub + latencySubBits - 1
+	m := uint64(i % latencySub)
+	return time.Duration((latencySub + m) << (e - latencySubBits))
+}
+
+func (h *latencyHistogram) add(i int, d time.Duration) {
+	d = max(d, 0)
+	h.counts[latencyBucket(d)].Add(1)
+	h.sum.add(i, int64(d))
+	for {
+		cur := h.min.Load()
+		if (cur != 0 && cur <= int64(d)+1) || h.min.CompareAndSwap(cur, int64(d)+1) {
+			break
+		}
+	}
+	for {
+		cur := h.max.Load()
+		if cur >= int64(d) || h.max.CompareAndSwap(cur, int64(d)) {
+			break
+		}
+	}
+}
+
+// take returns a snapshot and resets the histogram.
+func (h *latencyHistogram) take() latencyStats {
+	var s latencyStats
+	for i := range h.counts {
+		if c := h.counts[i].Swap(0); c > 0 {
+			s.counts[i] = c
+			s.n += int64(c)
+		}
+	}
+	s.sum = time.Duration(h.sum.take())
+	s.min = time.Duration(max(h.min.Swap(0)-1, 0))
+	s.max = time.Duration(h.max.Swap(0))
+	return s
+}
+
+func (s *latencyStats) avg() time.Duration {
+	if s.n == 0 {
+		return 0
+	}
+	return s.sum / time.Duration(s.n)
+}
+
+// quantile returns an estimate of quantile q in [0,1] using bucket midpoints
+// clamped to the observed range.
+func (s *latencyStats) quantile(q float64) time.Duration {
+	if s.n == 0 {
+		return 0
+	}
+	rank := uint64(q*float64(s.n-1)) + 1
+	var seen uint64
+	for i, c := range s.counts {
+		seen += c
+		if seen >= rank {
+			lo, hi := latencyBound(i), latencyBound(i+1)
+			return min(max(lo+(hi-lo)/2, s.min), s.max)
+		}
+	}
+	return s.max
+}
+
+// String renders interval statistics for progress lines.
+func (s *latencyStats) String() string {
+	if s.n == 0 {
+		return ""
+	}
+	return fmt.Sprintf("latency min=%s avg=%s p50=%s p95=%s max=%s",
+		roundLatency(s.min),
+		roundLatency(s.avg()),
+		roundLatency(s.quantile(0.5)),
+		roundLatency(s.quantile(0.95)),
+		roundLatency(s.max),
+	)
+}
+
+// roundLatency keeps about three significant digits.
+func roundLatency(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	case d >= time.Microsecond:
+		return d.Round(10 * time.Nanosecond)
+	default:
+		return d
+	}
+}
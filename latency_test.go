@@ -0,0 +1,134 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencyBucket(t *testing.T) {
+	tests := []struct {
+		in   time.Duration
+		want int
+	}{
+		{-5, 0},
+		{0, 0},
+		{1, 1},
+		{7, 7},
+		{8, 8},
+		{15, 15},
+		{16, 16},
+		{17, 16},
+		{18, 17},
+		{31, 23},
+		{32, 24},
+		{1 << 62, (62 - latencySubBits + 1) * latencySub},
+	}
+	for _, tt := range tests {
+		if got := latencyBucket(tt.in); got != tt.want {
+			t.Errorf("latencyBucket(%d) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLatencyBound(t *testing.T) {
+	tests := []struct {
+		in   int
+		want time.Duration
+	}{
+		{0, 0},
+		{7, 7},
+		{8, 8},
+		{15, 15},
+		{16, 16},
+		{17, 18},
+		{23, 30},
+		{24, 32},
+	}
+	for _, tt := range tests {
+		if got := latencyBound(tt.in); got != tt.want {
+			t.Errorf("latencyBound(%d) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+
+	// every duration falls between the bounds of its bucket and the
+	// bucket width stays within 12.5% of the lower bound
+	for _, d := range []time.Duration{
+		1, 9, 100, 1023, 1024, 1025, 999_999,
+		time.Microsecond, time.Millisecond, 1500 * time.Millisecond,
+		time.Minute, 24 * time.Hour, 1<<62 - 1,
+	} {
+		i := latencyBucket(d)
+		lo, hi := latencyBound(i), latencyBound(i+1)
+		if d < lo || d >= hi {
+			t.Errorf("duration %d outside bucket %d [%d, %d)", d, i, lo, hi)
+		}
+		if i >= latencySub && float64(hi-lo)/float64(lo) > 1.0/latencySub {
+			t.Errorf("bucket %d [%d, %d) too wide", i, lo, hi)
+		}
+		if latencyBucket(lo) != i {
+			t.Errorf("lower bound %d of bucket %d maps to bucket %d", lo, i, latencyBucket(lo))
+		}
+	}
+}
+
+func TestLatencyQuantile(t *testing.T) {
+	var h latencyHistogram
+	s := h.take()
+	if q := s.quantile(0.5); q != 0 {
+		t.Errorf("empty quantile = %s, want 0", q)
+	}
+	if str := s.String(); str != "" {
+		t.Errorf("empty String() = %q", str)
+	}
+
+	// single value quantiles are clamped to the observed range
+	h.add(0, 3*time.Millisecond)
+	s = h.take()
+	for _, q := range []float64{0, 0.5, 0.95, 1} {
+		if got := s.quantile(q); got != 3*time.Millisecond {
+			t.Errorf("single value quantile(%v) = %s, want 3ms", q, got)
+		}
+	}
+
+	// take resets the histogram
+	if s = h.take(); s.n != 0 || s.min != 0 || s.max != 0 || s.sum != 0 {
+		t.Errorf("histogram not reset: %+v", s)
+	}
+
+	// uniform 1ms..100ms
+	for i := 1; i <= 100; i++ {
+		h.add(i%counterStripes, time.Duration(i)*time.Millisecond)
+	}
+	s = h.take()
+	if s.n != 100 || s.min != time.Millisecond || s.max != 100*time.Millisecond {
+		t.Fatalf("n=%d min=%s max=%s", s.n, s.min, s.max)
+	}
+	if avg := s.avg(); avg != 50500*time.Microsecond {
+		t.Errorf("avg = %s, want 50.5ms", avg)
+	}
+	tests := []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0, time.Millisecond},
+		{0.5, 50 * time.Millisecond},
+		{0.95, 95 * time.Millisecond},
+		{0.99, 99 * time.Millisecond},
+		{1, 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		got := s.quantile(tt.q)
+		if got < s.min || got > s.max {
+			t.Errorf("quantile(%v) = %s outside [%s, %s]", tt.q, got, s.min, s.max)
+		}
+		if diff := float64(got-tt.want) / float64(tt.want); diff > 1.0/latencySub || diff < -1.0/latencySub {
+			t.Errorf("quantile(%v) = %s, want %s ±12.5%%", tt.q, got, tt.want)
+		}
+	}
+	if !(s.quantile(0.5) <= s.quantile(0.95) && s.quantile(0.95) <= s.quantile(1)) {
+		t.Error("quantiles not monotonic")
+	}
+}
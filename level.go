@@ -0,0 +1,70 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"sync/atomic"
+)
+
+const levelInherit = -1
+
+// LevelVar is a concurrency-safe level holder that can be shared between
+// loggers. A child LevelVar follows its parent until a level is set
+// explicitly, which lets level changes cascade from a logger to its clones.
+type LevelVar struct {
+	parent *LevelVar
+	val    atomic.Int32
+}
+
+// NewLevelVar returns a root LevelVar initialized to l.
+func NewLevelVar(l Level) *LevelVar {
+	v := &LevelVar{}
+	v.val.Store(int32(l))
+	return v
+}
+
+// Child returns a new LevelVar that inherits its level from v until
+// Set is called on the child.
+func (v *LevelVar) Child() *LevelVar {
+	c := &LevelVar{parent: v}
+	c.val.Store(levelInherit)
+	return c
+}
+
+// Level returns the current level. Inherited levels are resolved through
+// the parent chain.
+func (v *LevelVar) Level() Level {
+	for v != nil {
+		if l := v.val.Load(); l != levelInherit {
+			return Level(l)
+		}
+		v = v.parent
+	}
+	return LevelInfo
+}
+
+// Set sets an explicit level and stops following the parent. Invalid
+// levels are ignored.
+func (v *LevelVar) Set(l Level) {
+	if l != LevelInvalid {
+		v.val.Store(int32(l))
+	}
+}
+
+// Reset makes a child LevelVar follow its parent again. Reset has no
+// effect on root vars.
+func (v *LevelVar) Reset() {
+	if v.parent != nil {
+		v.val.Store(levelInherit)
+	}
+}
+
+// IsInherited reports whether the level is currently taken from the parent.
+func (v *LevelVar) IsInherited() bool {
+	return v.parent != nil && v.val.Load() == levelInherit
+}
+
+func (v *LevelVar) String() string {
+	return v.Level().String()
+}
@@ -5,6 +5,7 @@ package log
 
 import (
 	"io"
+	"runtime"
 	"sync/atomic"
 )
 
@@ -29,6 +30,17 @@ func NewMultiWriter(writers ...io.Writer) *MultiWriter {
 	return mw
 }
 
+// newOwnedMultiWriter returns a MultiWriter for w that closes w once the
+// MultiWriter becomes unreachable. Loggers derived from a backend share its
+// MultiWriter, so w stays open while any of them is in use.
+func newOwnedMultiWriter(w io.WriteCloser) *MultiWriter {
+	mw := NewMultiWriter(w)
+	runtime.SetFinalizer(mw, func(*MultiWriter) {
+		_ = w.Close()
+	})
+	return mw
+}
+
 // Write writes bytes to all writers and silently ignores all errors.
 func (mw *MultiWriter) Write(p []byte) (n int, err error) {
 	for _, w := range *mw.writers.Load() {
@@ -67,3 +79,18 @@ func (mw *MultiWriter) Remove(w io.Writer) {
 	new = new[:k]
 	mw.writers.Store(&new)
 }
+
+// Replace swaps writer old for w in place and reports whether old was found.
+func (mw *MultiWriter) Replace(old, w io.Writer) bool {
+	writers := *mw.writers.Load()
+	new := make([]io.Writer, len(writers))
+	copy(new, writers)
+	for i, ew := range new {
+		if ew == old {
+			new[i] = w
+			mw.writers.Store(&new)
+			return true
+		}
+	}
+	return false
+}
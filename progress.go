@@ -4,36 +4,93 @@
 package log
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const defaultProgressInterval = 10 * time.Second
 
+// ProgressLogger periodically logs the progress of long running jobs.
+//
+// Log and its variants only update atomic striped counters, so many
+// goroutines can report progress concurrently without contention. Only
+// the call that observes the end of an interval takes the lock to format
+// and write the progress line.
 type ProgressLogger struct {
 	sync.Mutex
 	action      string
 	event       string
+	unit        Unit
+	counters    atomic.Pointer[[]*progressCounter]
 	interval    time.Duration
-	calls       int64
-	events      int64
+	calls       stripedCounter
+	events      stripedCounter
+	done        stripedCounter
+	total       int64
+	started     time.Time
 	lastLogTime time.Time
+	lastEvent   time.Time
 	logger      Logger
+	due         atomic.Bool
+	timer       *time.Timer
+	cancel      context.CancelFunc
+	stopped     chan struct{}
+	useBar      bool
+	bar         *progressBar
+	name        string
+	registry    *ProgressRegistry
+	parent      *ProgressLogger
+	stages      []*ProgressLogger
+	finished    time.Time
+	latency     latencyHistogram
+	level       Level
+}
+
+// progressCounter is a named secondary counter.
+type progressCounter struct {
+	name   string
+	unit   Unit
+	events stripedCounter
+	done   stripedCounter
+}
+
+// format formats count n with the counter's unit and name.
+func (c *progressCounter) format(n int64) string {
+	return formatCount(c.name, c.unit, n)
+}
+
+func formatCount(name string, unit Unit, n int64) string {
+	if unit.IsBytes() {
+		return unit.Format(n)
+	}
+	return unit.Format(n) + " " + pluralize(name, n)
 }
 
 func NewProgressLogger(logger Logger) *ProgressLogger {
 	if logger == nil {
 		logger = Log
 	}
-	return &ProgressLogger{
+	interval := defaultProgressInterval
+	if b, ok := logger.(*Backend); ok && b.config != nil && b.config.ProgressInterval > 0 {
+		interval = b.config.ProgressInterval
+	}
+	now := time.Now()
+	p := &ProgressLogger{
 		action:      "Processed",
 		event:       "call",
-		interval:    defaultProgressInterval,
-		lastLogTime: time.Now(),
+		interval:    interval,
+		started:     now,
+		lastLogTime: now,
+		lastEvent:   now,
 		logger:      logger,
+		level:       LevelInfo,
 	}
+	p.arm()
+	return p
 }
 
 func (l *ProgressLogger) SetAction(action string) *ProgressLogger {
@@ -46,8 +103,84 @@ func (l *ProgressLogger) SetEvent(event string) *ProgressLogger {
 	return l
 }
 
+// SetLevel sets the level progress is logged at, defaults to info.
+func (l *ProgressLogger) SetLevel(level Level) *ProgressLogger {
+	if level < LevelFatal {
+		l.level = level
+	}
+	return l
+}
+
+// SetUnit sets the format of the main event counter.
+func (l *ProgressLogger) SetUnit(unit Unit) *ProgressLogger {
+	l.unit = unit
+	return l
+}
+
+// AddCounter registers an additional named counter that is reported next
+// to the main event counter. Counters with byte units are printed without
+// name, all others use name as singular noun.
+func (l *ProgressLogger) AddCounter(name string, unit Unit) *ProgressLogger {
+	l.Lock()
+	defer l.Unlock()
+	if l.counter(name) == nil {
+		l.addCounter(name, unit)
+	}
+	return l
+}
+
+// counterList returns all secondary counters.
+func (l *ProgressLogger) counterList() []*progressCounter {
+	if list := l.counters.Load(); list != nil {
+		return *list
+	}
+	return nil
+}
+
+func (l *ProgressLogger) counter(name string) *progressCounter {
+	for _, c := range l.counterList() {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// addCounter appends a counter. Callers must hold the lock.
+func (l *ProgressLogger) addCounter(name string, unit Unit) *progressCounter {
+	c := &progressCounter{name: name, unit: unit}
+	old := l.counterList()
+	list := make([]*progressCounter, len(old), len(old)+1)
+	copy(list, old)
+	list = append(list, c)
+	l.counters.Store(&list)
+	return c
+}
+
+// SetBar enables rendering a live progress bar in place of periodic
+// progress lines while started with Start. The bar is only used when the
+// logger writes to a terminal, other outputs keep receiving text lines.
+func (l *ProgressLogger) SetBar(enable bool) *ProgressLogger {
+	l.useBar = enable
+	return l
+}
+
 func (l *ProgressLogger) SetInterval(interval time.Duration) *ProgressLogger {
+	l.Lock()
+	defer l.Unlock()
 	l.interval = interval
+	if l.timer != nil {
+		l.arm()
+	}
+	return l
+}
+
+// SetTotal sets the expected total number of events which enables percent
+// done and ETA reporting.
+func (l *ProgressLogger) SetTotal(total int64) *ProgressLogger {
+	l.Lock()
+	defer l.Unlock()
+	l.total = total
 	return l
 }
 
@@ -58,51 +191,327 @@ func pluralize(str string, count int64) string {
 	return str
 }
 
-func (p *ProgressLogger) Log(n int, extra ...string) {
+// roundDuration rounds d to a precision suitable for log messages.
+func roundDuration(d time.Duration) time.Duration {
+	if d >= time.Second {
+		return d.Round(time.Second)
+	}
+	return d.Round(time.Millisecond)
+}
+
+// Start reports progress from a background goroutine once per interval
+// until ctx is canceled or Stop is called. Intervals without events are
+// reported as well so stalled pipelines remain visible. While started, Log
+// only counts events. Both ways of stopping flush remaining progress and
+// allow to start again.
+func (p *ProgressLogger) Start(ctx context.Context) {
 	p.Lock()
 	defer p.Unlock()
-	p.calls++
-	p.events += int64(n)
-	now := time.Now()
-	duration := now.Sub(p.lastLogTime)
-	if duration < p.interval || p.events == 0 {
+	if p.cancel != nil {
 		return
 	}
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.stopped = make(chan struct{})
+	if p.useBar {
+		p.bar = openProgressBar(p.logger)
+	}
+	go p.run(ctx, p.interval, p.bar, p.stopped)
+}
 
-	// Truncate the duration to 10s of milliseconds.
-	tDuration := duration.Truncate(10 * time.Millisecond)
+// Stop stops background reporting started with Start and flushes
+// remaining progress.
+func (p *ProgressLogger) Stop() {
+	p.Lock()
+	cancel, stopped, bar := p.cancel, p.stopped, p.bar
+	p.cancel, p.stopped, p.bar = nil, nil, nil
+	p.Unlock()
+	if cancel != nil {
+		cancel()
+		<-stopped
+	}
+	if bar != nil {
+		bar.close()
+	}
+	p.Flush()
+}
 
-	// Log information about the event.
-	suffix := ""
-	if ex := strings.Join(extra, " "); len(ex) > 0 {
-		suffix = fmt.Sprintf(" (%d %s, %s)", p.calls, pluralize("call", p.calls), ex)
+func (p *ProgressLogger) run(ctx context.Context, interval time.Duration, bar *progressBar, stopped chan struct{}) {
+	defer close(stopped)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// in bar mode redraw frequently and only roll interval counters
+	var redraw <-chan time.Time
+	if bar != nil {
+		t := time.NewTicker(progressBarRefresh)
+		defer t.Stop()
+		redraw = t.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			p.Lock()
+			if p.stopped != stopped {
+				// stopped by Stop which cleans up
+				p.Unlock()
+				return
+			}
+			cancel := p.cancel
+			p.cancel, p.stopped, p.bar = nil, nil, nil
+			p.Unlock()
+			cancel()
+			if bar != nil {
+				bar.close()
+			}
+			p.Flush()
+			return
+		case now := <-redraw:
+			p.Lock()
+			line := p.barLine(now)
+			p.Unlock()
+			bar.draw(line)
+		case now := <-ticker.C:
+			p.Lock()
+			if bar != nil {
+				p.reset(now, p.takeInterval())
+			} else {
+				p.report(now, "", false)
+			}
+			p.Unlock()
+		}
 	}
-	p.logger.Infof("%s %d %s in %s"+suffix,
-		p.action,
-		p.events,
-		pluralize(p.event, p.events),
-		tDuration,
-	)
+}
 
-	p.calls = 0
-	p.events = 0
-	p.lastLogTime = now
+// Add increments the named counter by n. Unknown counters are created
+// with UnitNone. Add does not report, call it before Log to include the
+// counts in the next progress line.
+func (p *ProgressLogger) Add(name string, n int64) {
+	c := p.counter(name)
+	if c == nil {
+		p.Lock()
+		if c = p.counter(name); c == nil {
+			c = p.addCounter(name, UnitNone)
+		}
+		p.Unlock()
+	}
+	i := stripe()
+	c.events.add(i, n)
+	c.done.add(i, n)
+}
+
+// Log counts n events. On stages the parent decides when to report.
+func (p *ProgressLogger) Log(n int, extra ...string) {
+	p.log(n, -1, extra)
 }
 
+// LogDuration counts n events that took d to process. Progress lines
+// include latency statistics for the interval.
+func (p *ProgressLogger) LogDuration(n int, d time.Duration, extra ...string) {
+	p.log(n, d, extra)
+}
+
+// Time runs fn, measures its duration and counts the number of events
+// returned by fn.
+func (p *ProgressLogger) Time(fn func() int) {
+	start := time.Now()
+	n := fn()
+	p.log(n, time.Since(start), nil)
+}
+
+func (p *ProgressLogger) log(n int, d time.Duration, extra []string) {
+	i := stripe()
+	p.calls.add(i, 1)
+	if n != 0 {
+		p.events.add(i, int64(n))
+		p.done.add(i, int64(n))
+	}
+	if d >= 0 {
+		p.latency.add(i, d)
+	}
+
+	// only the caller that observes the end of an interval reports
+	root := p.root()
+	if !root.due.Load() || !root.due.CompareAndSwap(true, false) {
+		return
+	}
+	root.Lock()
+	defer root.Unlock()
+	root.poll(extra)
+}
+
+// poll reports when the interval is over. Callers must hold the lock.
+func (p *ProgressLogger) poll(extra []string) {
+	if p.cancel != nil {
+		return
+	}
+	if !p.active() {
+		// keep waiting for the first event
+		p.due.Store(true)
+		return
+	}
+	p.report(time.Now(), strings.Join(extra, " "), false)
+}
+
+// arm schedules the end of the current interval. Callers must hold the
+// lock unless p is not yet shared.
+func (p *ProgressLogger) arm() {
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.due.Store(false)
+	p.timer = time.AfterFunc(p.interval, func() { p.due.Store(true) })
+}
+
+// Flush logs remaining progress. When stages exist, the line includes
+// the time spent in each stage.
 func (p *ProgressLogger) Flush() {
 	p.Lock()
 	defer p.Unlock()
-	if p.calls == 0 {
+	if p.calls.load() == 0 && len(p.stages) == 0 {
 		return
 	}
-	now := time.Now()
-	p.logger.Infof("%s %d %s in %s",
-		p.action,
-		p.events,
-		pluralize(p.event, p.events),
-		now.Sub(p.lastLogTime),
-	)
-	p.calls = 0
-	p.events = 0
+	p.report(time.Now(), "", true)
+}
+
+// progressInterval holds the counts of a reporting interval.
+type progressInterval struct {
+	calls    int64
+	events   int64
+	counters []int64
+	latency  latencyStats
+}
+
+// takeInterval returns and resets the counts of the current interval.
+// Callers must hold the lock.
+func (p *ProgressLogger) takeInterval() *progressInterval {
+	iv := &progressInterval{
+		calls:   p.calls.take(),
+		events:  p.events.take(),
+		latency: p.latency.take(),
+	}
+	for _, c := range p.counterList() {
+		iv.counters = append(iv.counters, c.events.take())
+	}
+	return iv
+}
+
+// report logs progress for the current interval and starts a new interval.
+// Callers must hold the lock.
+func (p *ProgressLogger) report(now time.Time, extra string, final bool) {
+	active := p.active()
+	iv := p.takeInterval()
+	if p.logger.IsJSON() {
+		p.reportJSON(now, iv, active, extra, final)
+	} else {
+		p.reportText(now, iv, active, extra, final)
+	}
+	p.reset(now, iv)
+}
+
+func (p *ProgressLogger) reportText(now time.Time, iv *progressInterval, active bool, extra string, final bool) {
+	duration := now.Sub(p.lastLogTime)
+
+	// Truncate the duration to 10s of milliseconds.
+	tDuration := duration.Truncate(10 * time.Millisecond)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s %s", p.action, p.unit.Format(iv.events), pluralize(p.event, iv.events))
+	for i, c := range p.counterList()[:len(iv.counters)] {
+		b.WriteString(", ")
+		b.WriteString(c.format(iv.counters[i]))
+	}
+	fmt.Fprintf(&b, " in %s", tDuration)
+
+	// Add current and average rate, percent done and ETA.
+	avg := rate(p.done.load(), now.Sub(p.started))
+	fmt.Fprintf(&b, ", %.1f/s (avg %.1f/s)", rate(iv.events, duration), avg)
+	if p.total > 0 {
+		fmt.Fprintf(&b, ", %.1f%% done", p.percent())
+		if eta := p.eta(avg); eta > 0 {
+			fmt.Fprintf(&b, ", ETA %s", roundDuration(eta))
+		}
+	}
+
+	if !active {
+		fmt.Fprintf(&b, ", stalled for %s", roundDuration(now.Sub(p.lastEvent)))
+	}
+
+	// Add stage progress or stage timings on final reports.
+	if len(p.stages) > 0 {
+		if final {
+			b.WriteString(", stages: ")
+			b.WriteString(p.stageTimings(now))
+		} else if status := p.stageStatus(); status != "" {
+			b.WriteString(" | ")
+			b.WriteString(status)
+		}
+	}
+
+	if lat := iv.latency.String(); len(lat) > 0 || len(extra) > 0 {
+		fmt.Fprintf(&b, " (%d %s", iv.calls, pluralize("call", iv.calls))
+		for _, s := range []string{lat, extra} {
+			if len(s) > 0 {
+				b.WriteString(", ")
+				b.WriteString(s)
+			}
+		}
+		b.WriteByte(')')
+	}
+	logAt(p.logger, p.level, b.String())
+}
+
+// logAt logs v with logger l at level lvl.
+func logAt(l Logger, lvl Level, v ...any) {
+	switch lvl {
+	case LevelTrace:
+		l.Trace(v...)
+	case LevelDebug:
+		l.Debug(v...)
+	case LevelWarn:
+		l.Warn(v...)
+	case LevelError:
+		l.Error(v...)
+	default:
+		l.Info(v...)
+	}
+}
+
+// reset starts a new reporting interval after iv was taken. Callers must
+// hold the lock.
+func (p *ProgressLogger) reset(now time.Time, iv *progressInterval) {
+	if iv.events > 0 {
+		p.lastEvent = now
+	}
 	p.lastLogTime = now
+	for _, s := range p.stages {
+		s.Lock()
+		s.reset(now, s.takeInterval())
+		s.Unlock()
+	}
+	if p.parent == nil {
+		p.arm()
+	}
+}
+
+// percent returns the share of total events done.
+func (p *ProgressLogger) percent() float64 {
+	return float64(p.done.load()) * 100 / float64(p.total)
+}
+
+// eta returns the estimated time until total events are done at rate avg
+// or zero when unknown.
+func (p *ProgressLogger) eta(avg float64) time.Duration {
+	left := p.total - p.done.load()
+	if p.total <= 0 || left <= 0 || avg <= 0 {
+		return 0
+	}
+	return time.Duration(float64(left) / avg * float64(time.Second))
+}
+
+// rate returns events per second.
+func rate(n int64, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / d.Seconds()
 }
@@ -0,0 +1,114 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mattn/go-isatty"
+)
+
+const (
+	progressBarRefresh = 200 * time.Millisecond
+	progressBarWidth   = 30
+	clearLine          = "\r\033[K"
+)
+
+// progressBar keeps a single status line at the bottom of a terminal.
+// It replaces the terminal in a logger's MultiWriter so that regular log
+// lines are printed above the bar without corrupting it.
+type progressBar struct {
+	mu   sync.Mutex
+	mw   *MultiWriter
+	out  *os.File
+	line string
+}
+
+// openProgressBar installs a progress bar on the first terminal logger
+// writes to. It returns nil when the logger writes to no terminal.
+func openProgressBar(logger Logger) *progressBar {
+	b, ok := logger.(*Backend)
+	if !ok {
+		return nil
+	}
+	mw, ok := b.log.Writer().(*MultiWriter)
+	if !ok {
+		return nil
+	}
+	for _, w := range *mw.writers.Load() {
+		f, ok := w.(*os.File)
+		if !ok || !(isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())) {
+			continue
+		}
+		bar := &progressBar{mw: mw, out: f}
+		if mw.Replace(f, bar) {
+			return bar
+		}
+	}
+	return nil
+}
+
+// Write clears the bar, writes p and redraws the bar below.
+func (b *progressBar) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, _ = io.WriteString(b.out, clearLine)
+	n, err := b.out.Write(p)
+	_, _ = io.WriteString(b.out, b.line)
+	return n, err
+}
+
+func (b *progressBar) draw(line string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.line = line
+	_, _ = io.WriteString(b.out, clearLine+line)
+}
+
+// close removes the bar and restores the original writer.
+func (b *progressBar) close() {
+	b.mw.Replace(b, b.out)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.line = ""
+	_, _ = io.WriteString(b.out, clearLine)
+}
+
+// barLine renders the progress bar status line. Callers must hold the lock.
+func (p *ProgressLogger) barLine(now time.Time) string {
+	var b strings.Builder
+	done := p.done.load()
+	avg := rate(done, now.Sub(p.started))
+	if p.total > 0 {
+		pct := min(p.percent(), 100)
+		fill := int(pct * progressBarWidth / 100)
+		b.WriteByte('[')
+		b.WriteString(strings.Repeat("=", fill))
+		if fill < progressBarWidth {
+			b.WriteByte('>')
+			b.WriteString(strings.Repeat(" ", progressBarWidth-fill-1))
+		}
+		fmt.Fprintf(&b, "] %5.1f%% %s/%s %s", pct, p.unit.Format(done), p.unit.Format(p.total), pluralize(p.event, p.total))
+	} else {
+		fmt.Fprintf(&b, "%s %s %s", p.action, p.unit.Format(done), pluralize(p.event, done))
+	}
+	for _, c := range p.counterList() {
+		b.WriteString(", ")
+		b.WriteString(c.format(c.done.load()))
+	}
+	fmt.Fprintf(&b, " %.1f/s", rate(p.events.load(), now.Sub(p.lastLogTime)))
+	if eta := p.eta(avg); eta > 0 {
+		fmt.Fprintf(&b, " ETA %s", roundDuration(eta))
+	}
+	if status := p.stageStatus(); status != "" {
+		b.WriteString(" | ")
+		b.WriteString(status)
+	}
+	return b.String()
+}
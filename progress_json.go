@@ -0,0 +1,106 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"time"
+)
+
+// progressStage is the JSON representation of a stage.
+type progressStage struct {
+	Name       string          `json:"name"`
+	Done       int64           `json:"done"`
+	Total      int64           `json:"total,omitempty"`
+	Percent    float64         `json:"percent,omitempty"`
+	DurationMs int64           `json:"duration_ms"`
+	Finished   bool            `json:"finished"`
+	Stages     []progressStage `json:"stages,omitempty"`
+}
+
+// reportJSON logs progress for the current interval as structured fields
+// for machine consumption. All durations, including the ETA, are integer
+// milliseconds and carry an _ms suffix like duration_ms. Callers must hold
+// the lock.
+func (p *ProgressLogger) reportJSON(now time.Time, iv *progressInterval, active bool, extra string, final bool) {
+	duration := now.Sub(p.lastLogTime)
+	done := p.done.load()
+	avg := rate(done, now.Sub(p.started))
+	fields := []Field{
+		{"action", p.action},
+		{"event", p.event},
+		{"count", iv.events},
+		{"calls", iv.calls},
+		{"duration_ms", duration.Milliseconds()},
+		{"rate", rate(iv.events, duration)},
+		{"avg_rate", avg},
+		{"done", done},
+		{"elapsed_ms", now.Sub(p.started).Milliseconds()},
+	}
+	if p.total > 0 {
+		fields = append(fields,
+			Field{"total", p.total},
+			Field{"percent", p.percent()},
+			Field{"eta_ms", p.eta(avg).Milliseconds()},
+		)
+	}
+	for i, c := range p.counterList()[:len(iv.counters)] {
+		fields = append(fields, Field{c.name, iv.counters[i]})
+	}
+	if !active {
+		fields = append(fields, Field{"stalled_ms", now.Sub(p.lastEvent).Milliseconds()})
+	}
+	if h := &iv.latency; h.n > 0 {
+		fields = append(fields,
+			Field{"latency_min_ms", durationMs(h.min)},
+			Field{"latency_avg_ms", durationMs(h.avg())},
+			Field{"latency_p50_ms", durationMs(h.quantile(0.5))},
+			Field{"latency_p95_ms", durationMs(h.quantile(0.95))},
+			Field{"latency_max_ms", durationMs(h.max)},
+		)
+	}
+	if len(p.stages) > 0 {
+		fields = append(fields, Field{"stages", p.stageList(now)})
+	}
+	if extra != "" {
+		fields = append(fields, Field{"extra", extra})
+	}
+	if final {
+		fields = append(fields, Field{"final", true})
+	}
+	logAt(p.logger.WithFields(fields...), p.level, "progress")
+}
+
+// stageList returns the JSON representation of all stages. Callers must
+// hold the lock.
+func (p *ProgressLogger) stageList(now time.Time) []progressStage {
+	list := make([]progressStage, 0, len(p.stages))
+	for _, s := range p.stages {
+		s.Lock()
+		end := now
+		if !s.finished.IsZero() {
+			end = s.finished
+		}
+		ps := progressStage{
+			Name:       s.name,
+			Done:       s.done.load(),
+			Total:      s.total,
+			DurationMs: end.Sub(s.started).Milliseconds(),
+			Finished:   !s.finished.IsZero(),
+		}
+		if s.total > 0 {
+			ps.Percent = s.percent()
+		}
+		if len(s.stages) > 0 {
+			ps.Stages = s.stageList(now)
+		}
+		list = append(list, ps)
+		s.Unlock()
+	}
+	return list
+}
+
+// durationMs converts d to fractional milliseconds.
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	DefaultProgressRegistry = NewProgressRegistry()
+)
+
+// ProgressRegistry tracks running ProgressLoggers by name so that a
+// service can list all jobs and their current state on demand, e.g.
+// from a debug endpoint.
+type ProgressRegistry struct {
+	mu  sync.RWMutex
+	reg map[string]*ProgressLogger
+}
+
+func NewProgressRegistry() *ProgressRegistry {
+	return &ProgressRegistry{
+		reg: make(map[string]*ProgressLogger),
+	}
+}
+
+func (r *ProgressRegistry) Add(name string, p *ProgressLogger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reg[name] = p
+}
+
+func (r *ProgressRegistry) Remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.reg, name)
+}
+
+// remove deletes name only when it is still registered for p.
+func (r *ProgressRegistry) remove(name string, p *ProgressLogger) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.reg[name] == p {
+		delete(r.reg, name)
+	}
+}
+
+func (r *ProgressRegistry) Get(name string) (*ProgressLogger, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	p, ok := r.reg[name]
+	return p, ok
+}
+
+// Snapshot returns the current state of all registered jobs sorted
+// by name.
+func (r *ProgressRegistry) Snapshot() []ProgressSnapshot {
+	r.mu.RLock()
+	list := make([]*ProgressLogger, 0, len(r.reg))
+	for _, p := range r.reg {
+		list = append(list, p)
+	}
+	r.mu.RUnlock()
+	snap := make([]ProgressSnapshot, len(list))
+	for i, p := range list {
+		snap[i] = p.Snapshot()
+	}
+	sort.Slice(snap, func(i, j int) bool { return snap[i].Name < snap[j].Name })
+	return snap
+}
+
+// ProgressSnapshot is the state of a ProgressLogger at a point in time.
+// Rate covers the current reporting interval, AvgRate the entire run.
+// Durations are integer milliseconds like in JSON progress events.
+type ProgressSnapshot struct {
+	Name      string             `json:"name"`
+	Action    string             `json:"action"`
+	Event     string             `json:"event"`
+	Done      int64              `json:"done"`
+	Total     int64              `json:"total,omitempty"`
+	Percent   float64            `json:"percent,omitempty"`
+	Counters  map[string]int64   `json:"counters,omitempty"`
+	Rate      float64            `json:"rate"`
+	AvgRate   float64            `json:"avg_rate"`
+	Started   time.Time          `json:"started"`
+	ElapsedMs int64              `json:"elapsed_ms"`
+	EtaMs     int64              `json:"eta_ms,omitempty"`
+	Finished  bool               `json:"finished"`
+	Stages    []ProgressSnapshot `json:"stages,omitempty"`
+}
+
+// Register adds p to registry r under name. A nil registry selects
+// DefaultProgressRegistry. Done removes p from the registry.
+func (p *ProgressLogger) Register(r *ProgressRegistry, name string) *ProgressLogger {
+	if r == nil {
+		r = DefaultProgressRegistry
+	}
+	p.Lock()
+	if p.registry != nil {
+		p.registry.remove(p.name, p)
+	}
+	p.registry, p.name = r, name
+	p.Unlock()
+	r.Add(name, p)
+	return p
+}
+
+// Snapshot returns the current counters, rates and timings of p and all
+// its stages. It is safe to call while other goroutines log progress.
+func (p *ProgressLogger) Snapshot() ProgressSnapshot {
+	p.Lock()
+	defer p.Unlock()
+	return p.snapshot(time.Now())
+}
+
+// snapshot builds a snapshot at now. Callers must hold the lock.
+func (p *ProgressLogger) snapshot(now time.Time) ProgressSnapshot {
+	end := now
+	if !p.finished.IsZero() {
+		end = p.finished
+	}
+	done := p.done.load()
+	avg := rate(done, end.Sub(p.started))
+	s := ProgressSnapshot{
+		Name:      p.name,
+		Action:    p.action,
+		Event:     p.event,
+		Done:      done,
+		Total:     p.total,
+		Rate:      rate(p.events.load(), now.Sub(p.lastLogTime)),
+		AvgRate:   avg,
+		Started:   p.started,
+		ElapsedMs: end.Sub(p.started).Milliseconds(),
+		Finished:  !p.finished.IsZero(),
+	}
+	if p.total > 0 {
+		s.Percent = p.percent()
+		s.EtaMs = p.eta(avg).Milliseconds()
+	}
+	if list := p.counterList(); len(list) > 0 {
+		s.Counters = make(map[string]int64, len(list))
+		for _, c := range list {
+			s.Counters[c.name] = c.done.load()
+		}
+	}
+	for _, st := range p.stages {
+		st.Lock()
+		s.Stages = append(s.Stages, st.snapshot(now))
+		st.Unlock()
+	}
+	return s
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestProgressRegistrySnapshot(t *testing.T) {
+	r := NewProgressRegistry()
+	p := NewProgressLogger(Disabled).SetTotal(100).Register(r, "sync")
+	p.Stage("download").Log(10)
+	p.Log(25)
+	time.Sleep(2 * time.Millisecond)
+
+	list := r.Snapshot()
+	if len(list) != 1 {
+		t.Fatalf("got %d snapshots", len(list))
+	}
+	s := list[0]
+	if s.Name != "sync" || s.Done != 25 || s.Percent != 25 || len(s.Stages) != 1 || s.Stages[0].Done != 10 {
+		t.Errorf("unexpected snapshot %+v", s)
+	}
+	if s.ElapsedMs < 2 || s.EtaMs <= 0 {
+		t.Errorf("elapsed_ms=%d eta_ms=%d", s.ElapsedMs, s.EtaMs)
+	}
+
+	buf, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(buf, &m); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"elapsed_ms", "eta_ms"} {
+		if _, ok := m[key]; !ok {
+			t.Errorf("missing key %s in %s", key, buf)
+		}
+	}
+
+	p.Done()
+	if list := r.Snapshot(); len(list) != 0 {
+		t.Errorf("finished job still registered: %+v", list)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Stage returns a child tracker for one phase of a multi-stage job. Stages
+// count events like any ProgressLogger but never log on their own. Instead
+// the root logger includes the progress of all running stages in its lines,
+// e.g. "sync > download 45% > verify 12%", and the time spent per stage in
+// its final Flush. Stages can be nested.
+func (p *ProgressLogger) Stage(name string) *ProgressLogger {
+	now := time.Now()
+	s := &ProgressLogger{
+		action:      p.action,
+		event:       p.event,
+		unit:        p.unit,
+		interval:    p.interval,
+		started:     now,
+		lastLogTime: now,
+		lastEvent:   now,
+		logger:      p.logger,
+		level:       p.level,
+		name:        name,
+		parent:      p,
+	}
+	p.Lock()
+	p.stages = append(p.stages, s)
+	p.Unlock()
+	return s
+}
+
+// Done marks a stage or job as finished which freezes its duration and
+// removes it from the registry it was registered with.
+func (p *ProgressLogger) Done() {
+	p.Lock()
+	if p.finished.IsZero() {
+		p.finished = time.Now()
+	}
+	r, name := p.registry, p.name
+	p.registry = nil
+	p.Unlock()
+	if r != nil {
+		r.remove(name, p)
+	}
+}
+
+// root returns the top-level logger of a stage tree.
+func (p *ProgressLogger) root() *ProgressLogger {
+	for p.parent != nil {
+		p = p.parent
+	}
+	return p
+}
+
+// active reports whether p or any of its stages counted events in the
+// current interval. Callers must hold the lock.
+func (p *ProgressLogger) active() bool {
+	if p.events.load() > 0 {
+		return true
+	}
+	for _, s := range p.stages {
+		s.Lock()
+		ok := s.active()
+		s.Unlock()
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// stageStatus renders the progress of all running stages. Callers must
+// hold the lock.
+func (p *ProgressLogger) stageStatus() string {
+	var list []string
+	for _, s := range p.stages {
+		s.Lock()
+		if s.finished.IsZero() {
+			status := s.name + " " + s.progress()
+			if sub := s.stageStatus(); sub != "" {
+				status += " > " + sub
+			}
+			list = append(list, status)
+		}
+		s.Unlock()
+	}
+	return strings.Join(list, ", ")
+}
+
+// stageTimings renders the duration of all stages. Callers must hold
+// the lock.
+func (p *ProgressLogger) stageTimings(now time.Time) string {
+	list := make([]string, 0, len(p.stages))
+	for _, s := range p.stages {
+		s.Lock()
+		timing := s.name + " "
+		if s.finished.IsZero() {
+			timing += roundDuration(now.Sub(s.started)).String() + " (running)"
+		} else {
+			timing += roundDuration(s.finished.Sub(s.started)).String()
+		}
+		if len(s.stages) > 0 {
+			timing += " [" + s.stageTimings(now) + "]"
+		}
+		list = append(list, timing)
+		s.Unlock()
+	}
+	return strings.Join(list, ", ")
+}
+
+// progress renders percent done when a total is known and the number
+// of events otherwise. Callers must hold the lock.
+func (p *ProgressLogger) progress() string {
+	if p.total > 0 {
+		return fmt.Sprintf("%.0f%%", p.percent())
+	}
+	done := p.done.load()
+	return p.unit.Format(done) + " " + pluralize(p.event, done)
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// mutexProgress is the lock-based counting used by ProgressLogger before
+// striped counters, kept as a baseline for benchmarks.
+type mutexProgress struct {
+	sync.Mutex
+	calls, events, done int64
+	lastLogTime         time.Time
+	lastEvent           time.Time
+	interval            time.Duration
+	latency             struct {
+		counts        [latencyBuckets]uint64
+		n             uint64
+		sum, min, max time.Duration
+	}
+}
+
+func (p *mutexProgress) log(n int, d time.Duration) {
+	p.Lock()
+	defer p.Unlock()
+	if d >= 0 {
+		h := &p.latency
+		if h.n == 0 || d < h.min {
+			h.min = d
+		}
+		if d > h.max {
+			h.max = d
+		}
+		h.n++
+		h.sum += d
+		h.counts[latencyBucket(d)]++
+	}
+	p.calls++
+	p.events += int64(n)
+	p.done += int64(n)
+	now := time.Now()
+	if n > 0 {
+		p.lastEvent = now
+	}
+	if now.Sub(p.lastLogTime) >= p.interval {
+		p.lastLogTime = now
+	}
+}
+
+func newBenchProgress() *ProgressLogger {
+	return NewProgressLogger(Disabled).SetInterval(time.Hour)
+}
+
+func BenchmarkProgressLog(b *testing.B) {
+	p := newBenchProgress()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			p.Log(1)
+		}
+	})
+}
+
+func BenchmarkProgressLogDuration(b *testing.B) {
+	p := newBenchProgress()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			p.LogDuration(1, time.Millisecond)
+		}
+	})
+}
+
+func BenchmarkProgressLogMutex(b *testing.B) {
+	p := &mutexProgress{interval: time.Hour, lastLogTime: time.Now()}
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			p.log(1, -1)
+		}
+	})
+}
+
+func BenchmarkProgressLogDurationMutex(b *testing.B) {
+	p := &mutexProgress{interval: time.Hour, lastLogTime: time.Now()}
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			p.log(1, time.Millisecond)
+		}
+	})
+}
+
+func TestProgressConcurrentCounts(t *testing.T) {
+	p := NewProgressLogger(Disabled).SetInterval(time.Millisecond)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				p.Add("bytes", 2)
+				p.LogDuration(1, time.Duration(i)*time.Microsecond)
+			}
+		}()
+	}
+	wg.Wait()
+	s := p.Snapshot()
+	if s.Done != 8000 || s.Counters["bytes"] != 16000 {
+		t.Errorf("done=%d bytes=%d, want 8000 and 16000", s.Done, s.Counters["bytes"])
+	}
+}
+
+func TestStageInheritsSettings(t *testing.T) {
+	p := NewProgressLogger(Disabled).SetEvent("block").SetUnit(UnitSI).SetLevel(LevelWarn)
+	s := p.Stage("download")
+	s.Log(40000)
+	if s.event != "block" || s.unit != UnitSI || s.level != LevelWarn {
+		t.Errorf("stage event=%q unit=%d level=%s", s.event, s.unit, s.level)
+	}
+	p.Lock()
+	status := p.stageStatus()
+	p.Unlock()
+	if status != "download 40k blocks" {
+		t.Errorf("stage status = %q", status)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RedactMask replaces secrets found in log messages and fields.
+const RedactMask = "[REDACTED]"
+
+// DefaultRedactPatterns match common secrets in free-form log messages.
+// When a pattern contains a capture group named secret, only that group is
+// masked, otherwise the entire match is replaced.
+var DefaultRedactPatterns = []string{
+	// bearer tokens in authorization headers
+	`(?i)\bbearer\s+(?P<secret>[a-z0-9\-._~+/]+=*)`,
+	// PEM encoded private keys
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`,
+	// passwords in URL user info
+	`(?i)\b[a-z][a-z0-9+.\-]*://[^:/@\s]*:(?P<secret>[^@\s/]+)@`,
+	// key/value pairs like password=x or Password:x as printed by %v
+	`(?i)\b(?:password|passwd|pwd|secret|token|api[_-]?key)["']?\s*[:=]\s*["']?(?P<secret>[^\s"'&,;}]+)`,
+}
+
+// DefaultRedactFields are field names whose values are always masked.
+var DefaultRedactFields = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"api_key",
+	"apikey",
+	"authorization",
+	"private_key",
+}
+
+// Redactor masks secrets in log messages and field values before entries
+// are written. Configure a redactor before attaching it to a logger, it is
+// not safe to add patterns or fields concurrently with logging.
+type Redactor struct {
+	patterns []*regexp.Regexp
+	fields   map[string]struct{}
+}
+
+// NewRedactor returns a redactor preloaded with DefaultRedactPatterns and
+// DefaultRedactFields.
+func NewRedactor() *Redactor {
+	r := &Redactor{
+		fields: make(map[string]struct{}),
+	}
+	for _, expr := range DefaultRedactPatterns {
+		r.patterns = append(r.patterns, regexp.MustCompile(expr))
+	}
+	r.AddFields(DefaultRedactFields...)
+	return r
+}
+
+// AddPattern compiles and adds a regular expression to the redactor.
+func (r *Redactor) AddPattern(expr string) error {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid redact pattern %q: %w", expr, err)
+	}
+	r.patterns = append(r.patterns, re)
+	return nil
+}
+
+// AddFields adds field names to the denylist. Names are matched case
+// insensitive and dashes are treated like underscores.
+func (r *Redactor) AddFields(names ...string) {
+	for _, n := range names {
+		r.fields[normalizeFieldName(n)] = struct{}{}
+	}
+}
+
+// Redact returns s with all pattern matches masked.
+func (r *Redactor) Redact(s string) string {
+	for _, re := range r.patterns {
+		s = redactPattern(re, s)
+	}
+	return s
+}
+
+// RedactEntry masks secrets in the message and fields of e in place.
+func (r *Redactor) RedactEntry(e *Entry) {
+	e.Message = r.Redact(e.Message)
+	for i, f := range e.Fields {
+		if _, ok := r.fields[normalizeFieldName(f.Key)]; ok {
+			e.Fields[i].Value = RedactMask
+			continue
+		}
+		if len(r.patterns) == 0 {
+			continue
+		}
+		v := fmt.Sprint(f.Value)
+		if rv := r.Redact(v); rv != v {
+			e.Fields[i].Value = rv
+		}
+	}
+}
+
+// Fire implements the Hook interface so a redactor can also run at a
+// custom position in a hook chain.
+func (r *Redactor) Fire(e *Entry) bool {
+	r.RedactEntry(e)
+	return true
+}
+
+func redactPattern(re *regexp.Regexp, s string) string {
+	group := re.SubexpIndex("secret")
+	if group < 0 {
+		return re.ReplaceAllLiteralString(s, RedactMask)
+	}
+	matches := re.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+	var (
+		b    strings.Builder
+		last int
+	)
+	for _, m := range matches {
+		start, end := m[2*group], m[2*group+1]
+		if start < 0 {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(RedactMask)
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func normalizeFieldName(n string) string {
+	return strings.ReplaceAll(strings.ToLower(n), "-", "_")
+}
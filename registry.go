@@ -4,11 +4,24 @@
 package log
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
-const wildcard = "*"
+const (
+	wildcard             = "*"
+	defaultWatchInterval = 5 * time.Second
+)
 
 var (
 	DefaultRegistry = NewRegistry()
@@ -72,3 +85,156 @@ func (r *Registry) SetLevels(tag string, lvl Level) {
 		}
 	}
 }
+
+// SetLevelMap applies all tag/level pairs in m. Tags may contain a wildcard.
+// Wildcard patterns are applied before exact tags so that exact entries take
+// precedence over broader patterns.
+func (r *Registry) SetLevelMap(m map[string]Level) {
+	tags := make([]string, 0, len(m))
+	for tag := range m {
+		tags = append(tags, tag)
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		wi, wj := strings.Contains(tags[i], wildcard), strings.Contains(tags[j], wildcard)
+		if wi != wj {
+			return wi
+		}
+		if len(tags[i]) != len(tags[j]) {
+			return len(tags[i]) < len(tags[j])
+		}
+		return tags[i] < tags[j]
+	})
+	for _, tag := range tags {
+		r.SetLevels(tag, m[tag])
+	}
+}
+
+// matchLevel returns the level for tag from a tag/level map using the same
+// precedence as SetLevelMap: exact tags first, then the longest matching
+// wildcard pattern.
+func matchLevel(m map[string]Level, tag string) (Level, bool) {
+	if lvl, ok := m[tag]; ok {
+		return lvl, true
+	}
+	var (
+		best  string
+		found bool
+	)
+	for pattern := range m {
+		prefix, suffix, ok := strings.Cut(pattern, wildcard)
+		if !ok || !strings.HasPrefix(tag, prefix) || !strings.HasSuffix(tag, suffix) {
+			continue
+		}
+		if !found || len(pattern) > len(best) || (len(pattern) == len(best) && pattern > best) {
+			best, found = pattern, true
+		}
+	}
+	return m[best], found
+}
+
+// WriteLevels encodes the current level map to w. Supported formats
+// are json and yaml.
+func (r *Registry) WriteLevels(w io.Writer, format string) error {
+	levels := r.GetLevels()
+	switch strings.ToLower(format) {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(levels)
+	case "yaml", "yml":
+		enc := yaml.NewEncoder(w)
+		defer enc.Close()
+		return enc.Encode(levels)
+	default:
+		return fmt.Errorf("unsupported level file format %q", format)
+	}
+}
+
+// ReadLevels decodes a level map from rd and applies it to all registered
+// loggers. Supported formats are json and yaml.
+func (r *Registry) ReadLevels(rd io.Reader, format string) error {
+	levels := make(map[string]Level)
+	switch strings.ToLower(format) {
+	case "json":
+		if err := json.NewDecoder(rd).Decode(&levels); err != nil {
+			return err
+		}
+	case "yaml", "yml":
+		if err := yaml.NewDecoder(rd).Decode(&levels); err != nil && err != io.EOF {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported level file format %q", format)
+	}
+	r.SetLevelMap(levels)
+	return nil
+}
+
+// SaveLevels writes the current level map to a file. The format is
+// derived from the file extension.
+func (r *Registry) SaveLevels(name string) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := r.WriteLevels(f, levelFileFormat(name)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadLevels reads a level map from a file and applies it. The format is
+// derived from the file extension.
+func (r *Registry) LoadLevels(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return r.ReadLevels(f, levelFileFormat(name))
+}
+
+// WatchLevels loads a level file and re-applies it in the background every
+// time its modification time or size changes. The file is polled in the given
+// interval which works well with Kubernetes ConfigMap volumes where files are
+// replaced through symlink swaps. Watching stops when ctx is canceled.
+func (r *Registry) WatchLevels(ctx context.Context, name string, interval time.Duration) error {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return err
+	}
+	if err := r.LoadLevels(name); err != nil {
+		return err
+	}
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				next, err := os.Stat(name)
+				if err != nil {
+					continue
+				}
+				if next.ModTime().Equal(fi.ModTime()) && next.Size() == fi.Size() {
+					continue
+				}
+				fi = next
+				if err := r.LoadLevels(name); err != nil {
+					Log.Errorf("reloading log levels from %s: %v", name, err)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func levelFileFormat(name string) string {
+	return strings.TrimPrefix(filepath.Ext(name), ".")
+}
@@ -5,6 +5,10 @@
 package log
 
 import (
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -18,16 +22,128 @@ var (
 	SampleFew = &Sampler{N: 1, Period: time.Minute}
 )
 
+// Sampling decides whether a log event passes. Implementations must be
+// safe for concurrent use. Samplers can be assigned per level, fatal and
+// panic messages are never sampled.
+//
+// Samplers shipped with this package keep per-logger state which is cloned
+// together with the logger, except for RandomSampler and AdaptiveSampler
+// which are shared. They also count dropped events and make loggers emit
+// a summary of suppressed messages. Custom implementations are shared
+// between clones.
+type Sampling interface {
+	Sample() bool
+}
+
+// SampleKey defines how a Sampler groups events into separate budgets.
+type SampleKey byte
+
+const (
+	// SampleByLogger uses a single budget for all events of a logger.
+	SampleByLogger SampleKey = iota
+	// SampleByCallSite uses a separate budget per source file and line.
+	SampleByCallSite
+	// SampleByFormat uses a separate budget per format string. Calls
+	// without format string fall back to their call site.
+	SampleByFormat
+)
+
+const (
+	defaultSampleMaxKeys        = 1024
+	defaultSampleReportInterval = 10 * time.Second
+)
+
 // Sampler lets a burst of N events pass per Period. If Period is 0,
 // every Nth event is allowed.
+//
+// Dropped events are counted. Loggers emit a summary of suppressed messages
+// with the next event that passes once per Period (or every 10 seconds when
+// Period is 0). Totals of cloned samplers also accumulate in their parent.
 type Sampler struct {
 	// N is the maximum number of event per period allowed.
 	N uint32
 	// Period defines the period.
 	Period time.Duration
+	// Key selects whether budgets are shared or tracked per call site
+	// or format string.
+	Key SampleKey
+	// MaxKeys limits the number of tracked keys, defaults to 1024.
+	MaxKeys int
 
-	counter atomic.Uint32
-	resetAt atomic.Int64
+	bucket sampleBucket
+	sampleStats
+
+	mu   sync.Mutex
+	keys map[string]*sampleBucket
+}
+
+// samplingCloner is implemented by samplers with per-logger state.
+type samplingCloner interface {
+	clone() Sampling
+}
+
+// formatSampling is implemented by samplers that use the format string
+// or call site of an event.
+type formatSampling interface {
+	sample(f string) bool
+}
+
+// suppressionReporter is implemented by samplers that count dropped events.
+type suppressionReporter interface {
+	suppressed() (uint64, time.Duration)
+}
+
+// samplerSet holds one sampler per level from trace to error. Fatal and
+// panic messages are never sampled.
+type samplerSet [LevelFatal]Sampling
+
+// Set assigns s to all listed levels or to all sampled levels when no
+// level is given.
+func (x *samplerSet) Set(s Sampling, levels ...Level) {
+	if sp, ok := s.(*Sampler); ok && sp == nil {
+		s = nil
+	}
+	if len(levels) == 0 {
+		for i := range x {
+			x[i] = s
+		}
+		return
+	}
+	for _, l := range levels {
+		if l < LevelFatal {
+			x[l] = s
+		}
+	}
+}
+
+// Clone clones all samplers with per-logger state. Levels sharing a
+// sampler in x share the cloned sampler in the result.
+func (x samplerSet) Clone() samplerSet {
+	var c samplerSet
+next:
+	for i, s := range x {
+		cs, ok := s.(samplingCloner)
+		if !ok {
+			c[i] = s
+			continue
+		}
+		for j := range i {
+			if x[j] == s {
+				c[i] = c[j]
+				continue next
+			}
+		}
+		c[i] = cs.clone()
+	}
+	return c
+}
+
+// sample applies sampler s to an event logged with format string f.
+func sample(s Sampling, f string) bool {
+	if fs, ok := s.(formatSampling); ok {
+		return fs.sample(f)
+	}
+	return s.Sample()
 }
 
 func (s *Sampler) Clone() *Sampler {
@@ -35,50 +151,213 @@ func (s *Sampler) Clone() *Sampler {
 		return s
 	}
 	return &Sampler{
-		N:      s.N,
-		Period: s.Period,
+		N:           s.N,
+		Period:      s.Period,
+		Key:         s.Key,
+		MaxKeys:     s.MaxKeys,
+		sampleStats: sampleStats{parent: &s.sampleStats},
 	}
 }
 
+func (s *Sampler) clone() Sampling {
+	return s.Clone()
+}
+
+func (s *Sampler) suppressed() (uint64, time.Duration) {
+	return s.report(s.Period)
+}
+
+// Sample reports whether the next event is allowed using the shared
+// budget of the sampler.
 func (s *Sampler) Sample() bool {
+	return s.sampleBucket(&s.bucket)
+}
+
+// SampleKey reports whether the next event for key is allowed. Each key
+// has its own budget.
+func (s *Sampler) SampleKey(key string) bool {
+	return s.sampleBucket(s.lookup(key))
+}
+
+// sample applies the sampler's key strategy to an event logged with
+// format string f, which is empty for non-format calls.
+func (s *Sampler) sample(f string) bool {
+	switch s.Key {
+	case SampleByFormat:
+		if f != "" {
+			return s.SampleKey(f)
+		}
+		return s.SampleKey(callSite())
+	case SampleByCallSite:
+		return s.SampleKey(callSite())
+	default:
+		return s.Sample()
+	}
+}
+
+func (s *Sampler) sampleBucket(b *sampleBucket) bool {
+	var ok bool
 	if s.Period == 0 {
-		return s.sampleSimple()
+		ok = s.sampleSimple(b)
+	} else {
+		ok = s.samplePeriod(b)
 	}
-	return s.samplePeriod()
+	s.count(ok)
+	return ok
 }
 
-func (s *Sampler) sampleSimple() bool {
+func (s *Sampler) sampleSimple(b *sampleBucket) bool {
 	n := s.N
 	if n == 1 {
 		return true
 	}
-	c := s.counter.Add(1)
+	c := b.counter.Add(1)
 	return c%n == 1
 }
 
-func (s *Sampler) samplePeriod() bool {
+func (s *Sampler) samplePeriod(b *sampleBucket) bool {
 	if s.N > 0 && s.Period > 0 {
-		if s.inc() <= s.N {
+		if b.inc(s.Period) <= s.N {
 			return true
 		}
 	}
 	return false
 }
 
-func (s *Sampler) inc() uint32 {
+// lookup returns the bucket for key. When the key limit is reached, expired
+// buckets are dropped first and an arbitrary bucket otherwise.
+func (s *Sampler) lookup(key string) *sampleBucket {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if b, ok := s.keys[key]; ok {
+		return b
+	}
+	if s.keys == nil {
+		s.keys = make(map[string]*sampleBucket)
+	}
+	limit := s.MaxKeys
+	if limit <= 0 {
+		limit = defaultSampleMaxKeys
+	}
+	if len(s.keys) >= limit {
+		now := time.Now().UnixNano()
+		for k, b := range s.keys {
+			if s.Period > 0 && b.resetAt.Load() < now {
+				delete(s.keys, k)
+			}
+		}
+		for k := range s.keys {
+			if len(s.keys) < limit {
+				break
+			}
+			delete(s.keys, k)
+		}
+	}
+	b := &sampleBucket{}
+	s.keys[key] = b
+	return b
+}
+
+// sampleBucket counts events in the current period.
+type sampleBucket struct {
+	counter atomic.Uint32
+	resetAt atomic.Int64
+}
+
+func (b *sampleBucket) inc(period time.Duration) uint32 {
 	now := time.Now().UnixNano()
-	resetAt := s.resetAt.Load()
+	resetAt := b.resetAt.Load()
 	var c uint32
 	if now > resetAt {
 		c = 1
-		s.counter.Store(1)
-		newResetAt := now + s.Period.Nanoseconds()
-		if !s.resetAt.CompareAndSwap(resetAt, newResetAt) {
+		b.counter.Store(1)
+		newResetAt := now + period.Nanoseconds()
+		if !b.resetAt.CompareAndSwap(resetAt, newResetAt) {
 			// Lost the race with another goroutine trying to reset.
-			c = s.counter.Add(1)
+			c = b.counter.Add(1)
 		}
 	} else {
-		c = s.counter.Add(1)
+		c = b.counter.Add(1)
 	}
 	return c
 }
+
+// sampleStats counts passed and dropped events. Counts propagate to the
+// stats of the sampler a sampler was cloned from.
+type sampleStats struct {
+	parent   *sampleStats
+	passed   atomic.Uint64
+	dropped  atomic.Uint64
+	pending  atomic.Uint64
+	since    atomic.Int64
+	reported atomic.Int64
+}
+
+// Passed returns the total number of events allowed by the sampler and
+// its clones.
+func (s *sampleStats) Passed() uint64 {
+	return s.passed.Load()
+}
+
+// Dropped returns the total number of events suppressed by the sampler
+// and its clones.
+func (s *sampleStats) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+func (s *sampleStats) count(ok bool) {
+	for p := s; p != nil; p = p.parent {
+		if ok {
+			p.passed.Add(1)
+		} else {
+			p.dropped.Add(1)
+		}
+	}
+	if !ok && s.pending.Add(1) == 1 {
+		s.since.Store(time.Now().UnixNano())
+	}
+}
+
+// report returns the number of events dropped since the last report
+// and the time span they cover. It reports at most once per interval.
+func (s *sampleStats) report(interval time.Duration) (uint64, time.Duration) {
+	if s.pending.Load() == 0 {
+		return 0, 0
+	}
+	if interval <= 0 {
+		interval = defaultSampleReportInterval
+	}
+	now := time.Now().UnixNano()
+	last := s.reported.Load()
+	if now-last < interval.Nanoseconds() || !s.reported.CompareAndSwap(last, now) {
+		return 0, 0
+	}
+	since := s.since.Load()
+	n := s.pending.Swap(0)
+	return n, time.Duration(now - since)
+}
+
+// pkgPrefix is the function name prefix of this package used to skip
+// internal frames when looking up call sites.
+var pkgPrefix = func() string {
+	pc, _, _, _ := runtime.Caller(0)
+	name := runtime.FuncForPC(pc).Name()
+	slash := strings.LastIndexByte(name, '/')
+	return name[:slash+strings.IndexByte(name[slash+1:], '.')+2]
+}()
+
+// callSite returns file:line of the first caller outside this package.
+func callSite() string {
+	var pcs [16]uintptr
+	n := runtime.Callers(2, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if !strings.HasPrefix(f.Function, pkgPrefix) {
+			return f.File + ":" + strconv.Itoa(f.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSamplerCountsAcrossClones(t *testing.T) {
+	root := &Sampler{N: 2, Period: time.Minute}
+	a, b := root.Clone(), root.Clone()
+	for range 5 {
+		a.Sample()
+	}
+	for range 3 {
+		b.Sample()
+	}
+	tests := []struct {
+		name            string
+		s               *Sampler
+		passed, dropped uint64
+	}{
+		{"a", a, 2, 3},
+		{"b", b, 2, 1},
+		{"root", root, 4, 4},
+	}
+	for _, tt := range tests {
+		if got := tt.s.Passed(); got != tt.passed {
+			t.Errorf("%s: Passed() = %d, want %d", tt.name, got, tt.passed)
+		}
+		if got := tt.s.Dropped(); got != tt.dropped {
+			t.Errorf("%s: Dropped() = %d, want %d", tt.name, got, tt.dropped)
+		}
+	}
+
+	// clones report their own drops, the parent only accumulates totals
+	if n, _ := root.suppressed(); n != 0 {
+		t.Errorf("root: suppressed() = %d, want 0", n)
+	}
+	if n, d := a.suppressed(); n != 3 || d <= 0 {
+		t.Errorf("a: suppressed() = %d, %s, want 3 and a positive span", n, d)
+	}
+}
+
+func TestSamplerEveryNth(t *testing.T) {
+	s := &Sampler{N: 3}
+	var got []bool
+	for range 7 {
+		got = append(got, s.Sample())
+	}
+	want := []bool{true, false, false, true, false, false, true}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Sample() sequence = %v, want %v", got, want)
+		}
+	}
+	if s.Passed() != 3 || s.Dropped() != 4 {
+		t.Errorf("Passed(), Dropped() = %d, %d, want 3, 4", s.Passed(), s.Dropped())
+	}
+}
+
+func TestSamplerReportInterval(t *testing.T) {
+	s := &Sampler{N: 1, Period: time.Minute}
+	if n, _ := s.suppressed(); n != 0 {
+		t.Fatalf("suppressed() without drops = %d, want 0", n)
+	}
+	s.Sample()
+	s.Sample()
+	s.Sample()
+	if n, _ := s.suppressed(); n != 2 {
+		t.Fatalf("first suppressed() = %d, want 2", n)
+	}
+	if n, _ := s.suppressed(); n != 0 {
+		t.Fatalf("suppressed() after report = %d, want 0", n)
+	}
+
+	// further drops are held back until the interval has passed
+	s.Sample()
+	if n, _ := s.suppressed(); n != 0 {
+		t.Fatalf("suppressed() within interval = %d, want 0", n)
+	}
+	s.reported.Store(time.Now().Add(-time.Minute).UnixNano())
+	if n, _ := s.suppressed(); n != 1 {
+		t.Fatalf("suppressed() after interval = %d, want 1", n)
+	}
+}
+
+func TestSamplerSummary(t *testing.T) {
+	var buf bytes.Buffer
+	l := newTestLogger(&buf, false).WithSampler(&Sampler{N: 2})
+	l.Info("a")
+	l.Info("b")
+	l.Info("c")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3:\n%s", len(lines), buf.String())
+	}
+	if lines[0] != "INFO a" || lines[2] != "INFO c" {
+		t.Errorf("unexpected output:\n%s", buf.String())
+	}
+	if !strings.HasPrefix(lines[1], "INFO suppressed 1 message in last ") {
+		t.Errorf("summary = %q, want suppressed 1 message", lines[1])
+	}
+}
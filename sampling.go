@@ -0,0 +1,191 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BurstSampler lets Burst events pass per Period and hands all further
+// events in the same period to Next. When Next is nil, they are dropped.
+type BurstSampler struct {
+	// Burst is the number of events allowed per period.
+	Burst uint32
+	// Period defines the period.
+	Period time.Duration
+	// Next is the fallback sampler used once the burst is exhausted.
+	Next Sampling
+
+	bucket sampleBucket
+	sampleStats
+}
+
+func (s *BurstSampler) Sample() bool {
+	ok := s.Burst > 0 && s.Period > 0 && s.bucket.inc(s.Period) <= s.Burst
+	if !ok && s.Next != nil {
+		ok = s.Next.Sample()
+	}
+	s.count(ok)
+	return ok
+}
+
+func (s *BurstSampler) clone() Sampling {
+	next := s.Next
+	if cs, ok := next.(samplingCloner); ok {
+		next = cs.clone()
+	}
+	return &BurstSampler{
+		Burst:       s.Burst,
+		Period:      s.Period,
+		Next:        next,
+		sampleStats: sampleStats{parent: &s.sampleStats},
+	}
+}
+
+func (s *BurstSampler) suppressed() (uint64, time.Duration) {
+	return s.report(s.Period)
+}
+
+// RandomSampler lets each event pass with probability Rate. It is shared
+// between clones.
+type RandomSampler struct {
+	rate float64
+
+	mu  sync.Mutex
+	rng *rand.Rand
+	sampleStats
+}
+
+// NewRandomSampler returns a sampler that passes events with probability
+// rate in [0, 1]. When src is nil a global random source is used, tests can
+// pass a seeded source to get deterministic results.
+func NewRandomSampler(rate float64, src rand.Source) *RandomSampler {
+	s := &RandomSampler{rate: rate}
+	if src != nil {
+		s.rng = rand.New(src)
+	}
+	return s
+}
+
+func (s *RandomSampler) Sample() bool {
+	ok := s.float64() < s.rate
+	s.count(ok)
+	return ok
+}
+
+func (s *RandomSampler) float64() float64 {
+	if s.rng == nil {
+		return rand.Float64()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rng.Float64()
+}
+
+func (s *RandomSampler) suppressed() (uint64, time.Duration) {
+	return s.report(0)
+}
+
+// TokenBucketSampler lets events pass at a sustained Rate per second with
+// bursts of up to Burst events.
+type TokenBucketSampler struct {
+	// Rate is the number of tokens added per second.
+	Rate float64
+	// Burst is the bucket capacity.
+	Burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	sampleStats
+}
+
+// NewTokenBucketSampler returns a token bucket sampler that starts full.
+func NewTokenBucketSampler(rate float64, burst int) *TokenBucketSampler {
+	return &TokenBucketSampler{
+		Rate:  rate,
+		Burst: float64(burst),
+	}
+}
+
+func (s *TokenBucketSampler) Sample() bool {
+	now := time.Now()
+	s.mu.Lock()
+	if s.last.IsZero() {
+		s.tokens = s.Burst
+	} else {
+		s.tokens = min(s.Burst, s.tokens+now.Sub(s.last).Seconds()*s.Rate)
+	}
+	s.last = now
+	ok := s.tokens >= 1
+	if ok {
+		s.tokens--
+	}
+	s.mu.Unlock()
+	s.count(ok)
+	return ok
+}
+
+func (s *TokenBucketSampler) clone() Sampling {
+	return &TokenBucketSampler{
+		Rate:        s.Rate,
+		Burst:       s.Burst,
+		sampleStats: sampleStats{parent: &s.sampleStats},
+	}
+}
+
+func (s *TokenBucketSampler) suppressed() (uint64, time.Duration) {
+	return s.report(0)
+}
+
+// AdaptiveSampler keeps the combined rate of all loggers sharing it close
+// to a target number of events per second. While the rate observed in the
+// previous second is below target all events pass, above target events pass
+// with probability target/observed. It is shared between clones, so setting
+// it on a root logger limits the entire logger tree.
+type AdaptiveSampler struct {
+	target float64
+
+	windowAt atomic.Int64
+	offered  atomic.Uint64
+	prob     atomic.Uint64
+	sampleStats
+}
+
+// NewAdaptiveSampler returns a sampler that targets at most target events
+// per second across all loggers.
+func NewAdaptiveSampler(target float64) *AdaptiveSampler {
+	s := &AdaptiveSampler{target: target}
+	s.prob.Store(math.Float64bits(1))
+	return s
+}
+
+func (s *AdaptiveSampler) Sample() bool {
+	now := time.Now().UnixNano()
+	start := s.windowAt.Load()
+	if now-start >= int64(time.Second) && s.windowAt.CompareAndSwap(start, now) {
+		p := 1.0
+		if observed := float64(s.offered.Swap(0)); observed > s.target {
+			p = s.target / observed
+			if start > 0 {
+				// scale by window length when events were sparse
+				p *= float64(now-start) / float64(time.Second)
+			}
+		}
+		s.prob.Store(math.Float64bits(min(p, 1)))
+	}
+	s.offered.Add(1)
+	p := math.Float64frombits(s.prob.Load())
+	ok := p >= 1 || rand.Float64() < p
+	s.count(ok)
+	return ok
+}
+
+func (s *AdaptiveSampler) suppressed() (uint64, time.Duration) {
+	return s.report(0)
+}
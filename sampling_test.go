@@ -0,0 +1,152 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func sampleN(s Sampling, n int) []bool {
+	res := make([]bool, n)
+	for i := range res {
+		res[i] = s.Sample()
+	}
+	return res
+}
+
+func equalBools(a, b []bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBurstSampler(t *testing.T) {
+	tests := []struct {
+		name string
+		s    *BurstSampler
+		want []bool
+	}{
+		{
+			name: "drop",
+			s:    &BurstSampler{Burst: 2, Period: time.Minute},
+			want: []bool{true, true, false, false, false},
+		},
+		{
+			name: "fallback",
+			s:    &BurstSampler{Burst: 2, Period: time.Minute, Next: &Sampler{N: 2}},
+			want: []bool{true, true, true, false, true},
+		},
+		{
+			name: "zero",
+			s:    &BurstSampler{Period: time.Minute},
+			want: []bool{false, false},
+		},
+	}
+	for _, tt := range tests {
+		if got := sampleN(tt.s, len(tt.want)); !equalBools(got, tt.want) {
+			t.Errorf("%s: Sample() sequence = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBurstSamplerClone(t *testing.T) {
+	root := &BurstSampler{Burst: 1, Period: time.Minute, Next: &Sampler{N: 1, Period: time.Minute}}
+	a := root.clone().(*BurstSampler)
+	b := root.clone().(*BurstSampler)
+	if a.Next == root.Next {
+		t.Fatal("clone shares Next sampler with parent")
+	}
+	sampleN(a, 3)
+	sampleN(b, 3)
+	if root.Passed() != 4 || root.Dropped() != 2 {
+		t.Errorf("root Passed(), Dropped() = %d, %d, want 4, 2", root.Passed(), root.Dropped())
+	}
+}
+
+func TestTokenBucketSampler(t *testing.T) {
+	s := NewTokenBucketSampler(2, 3)
+	want := []bool{true, true, true, false}
+	if got := sampleN(s, len(want)); !equalBools(got, want) {
+		t.Fatalf("Sample() sequence = %v, want %v", got, want)
+	}
+
+	// one second at rate 2 refills two tokens
+	s.mu.Lock()
+	s.last = s.last.Add(-time.Second)
+	s.mu.Unlock()
+	want = []bool{true, true, false}
+	if got := sampleN(s, len(want)); !equalBools(got, want) {
+		t.Fatalf("Sample() after refill = %v, want %v", got, want)
+	}
+
+	// refills never exceed the burst size
+	s.mu.Lock()
+	s.last = s.last.Add(-time.Hour)
+	s.mu.Unlock()
+	want = []bool{true, true, true, false}
+	if got := sampleN(s, len(want)); !equalBools(got, want) {
+		t.Fatalf("Sample() after long pause = %v, want %v", got, want)
+	}
+	if s.Passed() != 8 || s.Dropped() != 3 {
+		t.Errorf("Passed(), Dropped() = %d, %d, want 8, 3", s.Passed(), s.Dropped())
+	}
+}
+
+func TestAdaptiveSampler(t *testing.T) {
+	tests := []struct {
+		name    string
+		offered int
+		window  time.Duration
+		want    float64
+	}{
+		{"below target", 5, time.Second, 1},
+		{"at target", 10, time.Second, 1},
+		{"above target", 100, time.Second, 0.1},
+		{"sparse window", 100, 4 * time.Second, 0.4},
+		{"capped", 20, 4 * time.Second, 1},
+	}
+	for _, tt := range tests {
+		s := NewAdaptiveSampler(10)
+		s.Sample() // opens the first window
+		s.offered.Store(uint64(tt.offered))
+		s.windowAt.Store(time.Now().Add(-tt.window).UnixNano())
+		s.Sample() // closes it
+		if got := math.Float64frombits(s.prob.Load()); math.Abs(got-tt.want) > 0.01 {
+			t.Errorf("%s: probability = %.3f, want %.3f", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRandomSampler(t *testing.T) {
+	for _, rate := range []float64{0, 1} {
+		s := NewRandomSampler(rate, rand.NewPCG(1, 2))
+		for i, ok := range sampleN(s, 100) {
+			if ok != (rate == 1) {
+				t.Fatalf("rate %v: Sample() #%d = %v", rate, i, ok)
+			}
+		}
+	}
+
+	a := NewRandomSampler(0.3, rand.NewPCG(1, 2))
+	b := NewRandomSampler(0.3, rand.NewPCG(1, 2))
+	got, want := sampleN(a, 1000), sampleN(b, 1000)
+	if !equalBools(got, want) {
+		t.Fatal("samplers with equal seeds disagree")
+	}
+	if a.Passed()+a.Dropped() != 1000 {
+		t.Errorf("Passed()+Dropped() = %d, want 1000", a.Passed()+a.Dropped())
+	}
+	if p := a.Passed(); p < 250 || p > 350 {
+		t.Errorf("Passed() = %d, want about 300", p)
+	}
+}
@@ -6,64 +6,51 @@
 package log
 
 import (
+	"fmt"
 	stdlog "log"
 	"log/syslog"
-	"runtime"
 	"strings"
 )
 
 func NewSyslog(c *Config) *Backend {
+	b, err := newSyslog(c)
+	if err != nil {
+		stdlog.Fatalln("FATAL:", err)
+	}
+	return b
+}
+
+func newSyslog(c *Config) (*Backend, error) {
+	facility, err := syslogFacilityToEnum(c.Facility)
+	if err != nil {
+		return nil, err
+	}
+	var writer *syslog.Writer
 	if c.Addr != "" {
-		parts := strings.Split(c.Addr, "://")
-		if len(parts) != 2 {
-			stdlog.Fatalln("FATAL: Invalid syslog address. Must be of form protocol://path (e.g. unix:///dev/log)")
+		network, raddr, ok := strings.Cut(c.Addr, "://")
+		if !ok {
+			return nil, fmt.Errorf("invalid syslog address %q: must be of form protocol://path (e.g. unix:///dev/log)", c.Addr)
 		}
-
-		writer, err := syslog.Dial(
-			parts[0],
-			parts[1],
-			syslogFacilityToEnum(c.Facility)|syslog.LOG_INFO,
-			c.Ident,
-		)
+		writer, err = syslog.Dial(network, raddr, facility|syslog.LOG_INFO, c.Ident)
 		if err != nil {
-			stdlog.Fatalln("FATAL: Cannot open syslog address", c.Addr, ":", err.Error())
-		}
-		// don't 'print' date time
-		backend := &Backend{
-			level:  c.Level,
-			log:    stdlog.New(NewMultiWriter(writer), "", 0),
-			config: c,
+			return nil, fmt.Errorf("cannot open syslog address %s: %w", c.Addr, err)
 		}
-		runtime.SetFinalizer(backend, func(v any) {
-			b := v.(*Backend)
-			mw := b.log.Writer().(*MultiWriter)
-			_ = (*mw.writers.Load())[0].(*syslog.Writer).Close()
-		})
-		return backend
 	} else {
-		writer, err := syslog.New(
-			syslogFacilityToEnum(c.Facility)|syslog.LOG_INFO,
-			c.Ident,
-		)
+		writer, err = syslog.New(facility|syslog.LOG_INFO, c.Ident)
 		if err != nil {
-			stdlog.Fatalln("FATAL: Cannot open syslog:", err.Error())
-		}
-		// don't 'print' date time
-		backend := &Backend{
-			level:  c.Level,
-			log:    stdlog.New(NewMultiWriter(writer), "", 0),
-			config: c,
+			return nil, fmt.Errorf("cannot open syslog: %w", err)
 		}
-		runtime.SetFinalizer(backend, func(v any) {
-			b := v.(*Backend)
-			mw := b.log.Writer().(*MultiWriter)
-			_ = (*mw.writers.Load())[0].(*syslog.Writer).Close()
-		})
-		return backend
 	}
+	// don't 'print' date time
+	return &Backend{
+		level:  NewLevelVar(c.Level),
+		log:    stdlog.New(newOwnedMultiWriter(writer), "", 0),
+		config: c,
+		json:   c.IsJSON(),
+	}, nil
 }
 
-func syslogFacilityToEnum(f string) (p syslog.Priority) {
+func syslogFacilityToEnum(f string) (p syslog.Priority, err error) {
 	switch strings.ToLower(f) {
 	case "kern":
 		p = syslog.LOG_KERN
@@ -106,7 +93,7 @@ func syslogFacilityToEnum(f string) (p syslog.Priority) {
 	case "local7":
 		p = syslog.LOG_LOCAL7
 	default:
-		stdlog.Fatalln("FATAL: Invalid syslog facility", f)
+		err = fmt.Errorf("invalid syslog facility %q", f)
 	}
 	return
 }
@@ -10,9 +10,15 @@ import (
 
 // no syslog on windows, write to stdout
 func NewSyslog(c *Config) *Backend {
+	b, _ := newSyslog(c)
+	return b
+}
+
+func newSyslog(c *Config) (*Backend, error) {
 	return &Backend{
-		level:  c.Level,
-		log:    stdlog.New(NewMultiWriter(os.Stdout), "", c.Flags),
+		level:  NewLevelVar(c.Level),
+		log:    stdlog.New(NewMultiWriter(os.Stdout), "", c.LogFlags()),
 		config: c,
-	}
+		json:   c.IsJSON(),
+	}, nil
 }
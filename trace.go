@@ -0,0 +1,93 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// Field names used for trace correlation.
+const (
+	FieldTraceID = "trace_id"
+	FieldSpanID  = "span_id"
+)
+
+// TraceExtractor returns the trace and span ids associated with ctx.
+// Empty strings indicate that ctx carries no trace.
+type TraceExtractor func(ctx context.Context) (traceID, spanID string)
+
+// DefaultTraceExtractor is used by loggers without their own extractor.
+var DefaultTraceExtractor TraceExtractor = TraceparentExtractor
+
+var ErrInvalidTraceparent = errors.New("invalid traceparent")
+
+type traceparentKey struct{}
+
+// ContextWithTraceparent returns a copy of ctx that carries a W3C
+// traceparent header value, e.g. taken from an incoming HTTP request.
+func ContextWithTraceparent(ctx context.Context, header string) context.Context {
+	return context.WithValue(ctx, traceparentKey{}, header)
+}
+
+// TraceparentFromContext returns the traceparent header value stored in ctx.
+func TraceparentFromContext(ctx context.Context) string {
+	s, _ := ctx.Value(traceparentKey{}).(string)
+	return s
+}
+
+// TraceparentExtractor is a TraceExtractor that parses the W3C traceparent
+// value stored in ctx by ContextWithTraceparent.
+func TraceparentExtractor(ctx context.Context) (string, string) {
+	s := TraceparentFromContext(ctx)
+	if s == "" {
+		return "", ""
+	}
+	traceID, spanID, err := ParseTraceparent(s)
+	if err != nil {
+		return "", ""
+	}
+	return traceID, spanID
+}
+
+// ParseTraceparent parses a W3C traceparent header value of the form
+// version-traceid-parentid-flags and returns trace id and parent span id.
+func ParseTraceparent(s string) (traceID, spanID string, err error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 {
+		return "", "", ErrInvalidTraceparent
+	}
+	version, traceID, spanID, flags := parts[0], parts[1], parts[2], parts[3]
+	switch {
+	case !isHex(version, 2) || version == "ff":
+		return "", "", ErrInvalidTraceparent
+	case version == "00" && len(parts) != 4:
+		return "", "", ErrInvalidTraceparent
+	case !isHex(traceID, 32) || isZero(traceID):
+		return "", "", ErrInvalidTraceparent
+	case !isHex(spanID, 16) || isZero(spanID):
+		return "", "", ErrInvalidTraceparent
+	case !isHex(flags, 2):
+		return "", "", ErrInvalidTraceparent
+	}
+	return traceID, spanID, nil
+}
+
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func isZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2026 KIDTSUNAMI
+// Author: alex@blockwatch.cc
+
+package log
+
+import (
+	"context"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	const (
+		trace = "4bf92f3577b34da6a3ce929d0e0e4736"
+		span  = "00f067aa0ba902b7"
+	)
+	tests := []struct {
+		name  string
+		in    string
+		trace string
+		span  string
+		err   bool
+	}{
+		{"valid", "00-" + trace + "-" + span + "-01", trace, span, false},
+		{"unsampled", "00-" + trace + "-" + span + "-00", trace, span, false},
+		{"whitespace", " 00-" + trace + "-" + span + "-01\t", trace, span, false},
+		{"future version", "01-" + trace + "-" + span + "-01", trace, span, false},
+		{"future version extra fields", "cc-" + trace + "-" + span + "-01-what-the-future-will-be", trace, span, false},
+		{"version 00 extra field", "00-" + trace + "-" + span + "-01-extra", "", "", true},
+		{"version ff", "ff-" + trace + "-" + span + "-01", "", "", true},
+		{"version not hex", "0g-" + trace + "-" + span + "-01", "", "", true},
+		{"version too long", "000-" + trace + "-" + span + "-01", "", "", true},
+		{"zero trace id", "00-00000000000000000000000000000000-" + span + "-01", "", "", true},
+		{"zero span id", "00-" + trace + "-0000000000000000-01", "", "", true},
+		{"uppercase trace id", "00-4BF92F3577B34DA6A3CE929D0E0E4736-" + span + "-01", "", "", true},
+		{"uppercase span id", "00-" + trace + "-00F067AA0BA902B7-01", "", "", true},
+		{"uppercase flags", "00-" + trace + "-" + span + "-0A", "", "", true},
+		{"short trace id", "00-" + trace[1:] + "-" + span + "-01", "", "", true},
+		{"short span id", "00-" + trace + "-" + span[1:] + "-01", "", "", true},
+		{"missing flags", "00-" + trace + "-" + span, "", "", true},
+		{"empty", "", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			traceID, spanID, err := ParseTraceparent(tt.in)
+			if (err != nil) != tt.err {
+				t.Fatalf("ParseTraceparent(%q) error = %v, want error %t", tt.in, err, tt.err)
+			}
+			if err != nil && err != ErrInvalidTraceparent {
+				t.Errorf("ParseTraceparent(%q) error = %v, want %v", tt.in, err, ErrInvalidTraceparent)
+			}
+			if traceID != tt.trace || spanID != tt.span {
+				t.Errorf("ParseTraceparent(%q) = %q, %q, want %q, %q", tt.in, traceID, spanID, tt.trace, tt.span)
+			}
+		})
+	}
+}
+
+func TestTraceparentExtractor(t *testing.T) {
+	ctx := context.Background()
+	if traceID, spanID := TraceparentExtractor(ctx); traceID != "" || spanID != "" {
+		t.Errorf("empty context: got %q, %q", traceID, spanID)
+	}
+	ctx = ContextWithTraceparent(ctx, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	traceID, spanID := TraceparentExtractor(ctx)
+	if traceID != "4bf92f3577b34da6a3ce929d0e0e4736" || spanID != "00f067aa0ba902b7" {
+		t.Errorf("got %q, %q", traceID, spanID)
+	}
+	ctx = ContextWithTraceparent(ctx, "invalid")
+	if traceID, spanID := TraceparentExtractor(ctx); traceID != "" || spanID != "" {
+		t.Errorf("invalid header: got %q, %q", traceID, spanID)
+	}
+}